@@ -32,6 +32,8 @@ type accountRoot struct {
 
 // Get fetches an account.
 func (s *AccountsService) Get(ctx context.Context, account string) (*Account, *Response, error) {
+	ctx = withOperation(ctx, "Accounts.Get")
+
 	u := fmt.Sprintf("api/v1/accounts/%s", account)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -43,10 +43,10 @@ func (o CategoryLocale) String() string {
 
 // CategoryProvider represents the provider of the category.
 type CategoryProvider struct {
-	ID     *string `json:"id,omitempty"`
-	Name   *string `json:"name,omitempty"`
-	Type   *string `json:"type,omitempty"`
-	Method *string `json:"method,omitempty"`
+	ID     *string         `json:"id,omitempty"`
+	Name   *string         `json:"name,omitempty"`
+	Type   *ProviderType   `json:"type,omitempty"`
+	Method *ProviderMethod `json:"method,omitempty"`
 }
 
 func (c CategoryProvider) String() string {
@@ -62,7 +62,7 @@ type CategoriesListOptions struct {
 	Cursor   string                `url:"cursor,omitempty"`
 	Limit    int                   `url:"limit,omitempty"`
 	Locales  []string              `url:"locales[],omitempty"`
-	Metadata map[string]string     `url:"metadata,omitempty"`
+	Metadata MetadataFilter        `url:"metadata,omitempty"`
 	Slug     string                `url:"slug,omitempty"`
 	Sort     CategoriesSortOptions `url:"sort,omitempty"`
 }
@@ -78,6 +78,15 @@ type categoriesRoot struct {
 
 // List lists all categories
 func (s *CategoriesService) List(ctx context.Context, opts *CategoriesListOptions) ([]*Category, *Response, error) {
+	ctx = withOperation(ctx, "Categories.List")
+
+	var o CategoriesListOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Locales = s.client.localesFilter(ctx, o.Locales)
+	opts = &o
+
 	u, err := addOptions("api/v1/categories", opts)
 	if err != nil {
 		return nil, nil, err
@@ -99,8 +108,52 @@ func (s *CategoriesService) List(ctx context.Context, opts *CategoriesListOption
 	return root.Categories, resp, nil
 }
 
+// CategoriesIterator iterates over categories, following the pagination cursors
+// automatically.
+type CategoriesIterator struct {
+	iterator
+	page []*Category
+}
+
+// Value returns the current category. It must only be called after a call
+// to Next returned true.
+func (it *CategoriesIterator) Value() *Category {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *CategoriesIterator) Collect() ([]*Category, error) {
+	var items []*Category
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAll returns an iterator over all categories, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *CategoriesService) ListAll(ctx context.Context, opts *CategoriesListOptions) *CategoriesIterator {
+	var o CategoriesListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(CategoriesIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.List(ctx, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
 // Get fetches a category.
 func (s *CategoriesService) Get(ctx context.Context, category string) (*Category, *Response, error) {
+	ctx = withOperation(ctx, "Categories.Get")
+
 	u := fmt.Sprintf("api/v1/categories/%s", category)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -36,8 +36,8 @@ func TestCategory_marshal(t *testing.T) {
 					{
 						ID:     String("c61320df-9d9c-4738-b4c1-12db3f41af6c"),
 						Name:   String("Email"),
-						Type:   String("email"),
-						Method: String("single"),
+						Type:   ProviderTypeEmail.Ptr(),
+						Method: ProviderMethodSingle.Ptr(),
 					},
 				},
 
@@ -118,13 +118,13 @@ func TestCategoriesService_List(t *testing.T) {
 
 	mux.HandleFunc("/api/v1/categories", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
-		testFormValues(t, r, url.Values{"sort[created_at]": {"desc"}, "locales[]": {"nl-NL"}})
+		testFormValues(t, r, url.Values{"sort[created_at]": {"desc"}, "locales[]": {"nl-NL"}, "metadata[foo]": {"bar"}})
 
 		fmt.Fprint(w, `{"categories": [{"id":"b"}], "metadata": {"next_cursor": "def", "previous_cursor": "abc"}}`)
 	})
 
 	ctx := context.Background()
-	opts := &CategoriesListOptions{Sort: CategoriesSortOptions{CreatedAt: "desc"}, Locales: []string{"nl-NL"}}
+	opts := &CategoriesListOptions{Sort: CategoriesSortOptions{CreatedAt: "desc"}, Locales: []string{"nl-NL"}, Metadata: MetadataFilter{"foo": "bar"}}
 	categories, resp, err := client.Categories.List(ctx, opts)
 	if err != nil {
 		t.Errorf("Categories.List returned error: %v", err)
@@ -150,6 +150,32 @@ func TestCategoriesService_List(t *testing.T) {
 	})
 }
 
+func TestCategoriesService_ListAll(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/categories", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("cursor") == "" {
+			testFormValues(t, r, url.Values{"limit": {"1"}})
+			fmt.Fprint(w, `{"categories": [{"id":"a"}], "metadata": {"next_cursor": "def"}}`)
+			return
+		}
+		testFormValues(t, r, url.Values{"cursor": {"def"}, "limit": {"1"}})
+		fmt.Fprint(w, `{"categories": [{"id":"b"}], "metadata": {"previous_cursor": "abc"}}`)
+	})
+
+	ctx := context.Background()
+	got, err := client.Categories.ListAll(ctx, &CategoriesListOptions{Limit: 1}).Collect()
+	if err != nil {
+		t.Fatalf("Categories.ListAll returned error: %v", err)
+	}
+
+	if len(got) != 2 || *got[0].ID != "a" || *got[1].ID != "b" {
+		t.Errorf("Categories.ListAll returned %+v, want items a and b", got)
+	}
+}
+
 func TestCategoriesService_Get(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -0,0 +1,203 @@
+package gocancel
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxDownloadResumes is the maximum number of times a download is resumed
+// after the transfer of the response body fails.
+const maxDownloadResumes = 5
+
+// Download describes a file downloaded by DownloadDocumentTo or
+// DownloadProofOfIDTo.
+type Download struct {
+	// ContentType is the media type of the file.
+	ContentType string
+
+	// ContentLength is the size of the file in bytes.
+	ContentLength int64
+
+	// Filename is the suggested filename, if provided by the API.
+	Filename string
+
+	// Checksum is the SHA-256 checksum of the file.
+	Checksum []byte
+}
+
+func (d Download) String() string {
+	return Stringify(d)
+}
+
+// downloadTo sends req and writes the response body into w, resuming the
+// transfer when it fails partway.
+func (c *Client) downloadTo(ctx context.Context, req *http.Request, w io.WriterAt) (*Download, *Response, error) {
+	var (
+		d       *Download
+		resp    *Response
+		offset  int64
+		etag    string
+		sum     hash.Hash
+		resumes int
+	)
+
+	for {
+		r := req.Clone(ctx)
+		if offset > 0 {
+			r.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			if etag != "" {
+				r.Header.Set("If-Range", etag)
+			}
+		}
+
+		var err error
+		resp, err = c.BareDo(ctx, r)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		start, total, ok := contentRange(resp.Response)
+		if !ok || start != offset {
+			// The whole file is sent, e.g. because the server does not
+			// support ranges or the file has changed, start over.
+			if offset > 0 {
+				if err := truncate(w); err != nil {
+					resp.Body.Close()
+					return nil, resp, err
+				}
+			}
+			start, total, offset = 0, resp.ContentLength, 0
+			d = nil
+		}
+		if d == nil {
+			d = newDownload(resp.Response, total)
+			etag = resp.Header.Get("ETag")
+			sum = sha256.New()
+		}
+
+		n, err := io.Copy(io.MultiWriter(&offsetWriter{w: w, offset: start}, sum), resp.Body)
+		resp.Body.Close()
+		offset += n
+
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, resp, ctx.Err()
+		}
+
+		var werr *writeAtError
+		if errors.As(err, &werr) || !retryableError(err) || resumes >= maxDownloadResumes {
+			return nil, resp, err
+		}
+
+		resumes++
+		if c.retryPolicy != nil {
+			if err := sleep(ctx, c.retryPolicy.backoff(resumes)); err != nil {
+				return nil, resp, err
+			}
+		}
+	}
+
+	if d.ContentLength >= 0 && offset != d.ContentLength {
+		return nil, resp, fmt.Errorf("download incomplete: got %d of %d bytes", offset, d.ContentLength)
+	}
+
+	d.ContentLength = offset
+	d.Checksum = sum.Sum(nil)
+
+	return d, resp, nil
+}
+
+// truncate discards the bytes written into w by an earlier attempt of a
+// download that has to start over, so no stale bytes remain beyond the end of
+// the file sent again.
+func truncate(w io.WriterAt) error {
+	t, ok := w.(interface{ Truncate(size int64) error })
+	if !ok {
+		return errors.New("download restarted but the partially written file cannot be truncated")
+	}
+	if err := t.Truncate(0); err != nil {
+		return fmt.Errorf("truncating partially written file: %w", err)
+	}
+
+	return nil
+}
+
+// newDownload returns a Download described by the headers of r, for a file
+// of total bytes.
+func newDownload(r *http.Response, total int64) *Download {
+	d := &Download{
+		ContentType:   r.Header.Get("Content-Type"),
+		ContentLength: total,
+	}
+
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+		d.Filename = params["filename"]
+	}
+
+	return d
+}
+
+// contentRange returns the start offset and the total size of the file from
+// the Content-Range header of a 206 Partial Content response. The total is -1
+// if unknown.
+func contentRange(r *http.Response) (start, total int64, ok bool) {
+	if r.StatusCode != http.StatusPartialContent {
+		return 0, 0, false
+	}
+
+	// Content-Range: bytes <start>-<end>/<total or *>
+	v := strings.TrimPrefix(r.Header.Get("Content-Range"), "bytes ")
+	i := strings.IndexByte(v, '-')
+	j := strings.IndexByte(v, '/')
+	if i < 0 || j < i {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(v[:i], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	total = -1
+	if size := v[j+1:]; size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return start, total, true
+}
+
+// offsetWriter writes sequentially to an io.WriterAt, starting at offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.offset)
+	ow.offset += int64(n)
+	if err != nil {
+		return n, &writeAtError{err}
+	}
+	return n, nil
+}
+
+// writeAtError wraps errors writing the downloaded file, which unlike errors
+// reading the response are not worth resuming the download for.
+type writeAtError struct {
+	err error
+}
+
+func (e *writeAtError) Error() string { return e.err.Error() }
+func (e *writeAtError) Unwrap() error { return e.err }
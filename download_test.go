@@ -0,0 +1,243 @@
+package gocancel
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writerAt is an in-memory io.WriterAt.
+type writerAt struct {
+	buf []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(w.buf) {
+		w.buf = append(w.buf, make([]byte, end-len(w.buf))...)
+	}
+	return copy(w.buf[off:], p), nil
+}
+
+func (w *writerAt) Truncate(size int64) error {
+	w.buf = w.buf[:size]
+	return nil
+}
+
+// fixedWriterAt is an in-memory io.WriterAt that cannot be truncated.
+type fixedWriterAt struct {
+	w writerAt
+}
+
+func (w *fixedWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return w.w.WriteAt(p, off)
+}
+
+var testDocument = bytes.Repeat([]byte("%PDF-1.4 cancellation letter\n"), 100)
+
+func TestLettersService_DownloadDocumentTo(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testHeader(t, r, "Accept", mediaTypeLetterDocument)
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="letter.pdf"`)
+		w.Write(testDocument)
+	})
+
+	w := new(writerAt)
+	got, resp, err := client.Letters.DownloadDocumentTo(context.Background(), "b", w)
+	if err != nil {
+		t.Fatalf("Letters.DownloadDocumentTo returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Letters.DownloadDocumentTo returned status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	checksum := sha256.Sum256(testDocument)
+	want := &Download{
+		ContentType:   "application/pdf",
+		ContentLength: int64(len(testDocument)),
+		Filename:      "letter.pdf",
+		Checksum:      checksum[:],
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Letters.DownloadDocumentTo returned %+v, want %+v", got, want)
+	}
+	if !bytes.Equal(w.buf, testDocument) {
+		t.Errorf("Letters.DownloadDocumentTo wrote %q, want %q", w.buf, testDocument)
+	}
+
+	const methodName = "DownloadDocumentTo"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Letters.DownloadDocumentTo(context.Background(), "\n", w)
+		return err
+	})
+}
+
+func TestLettersService_DownloadDocumentTo_resume(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	half := len(testDocument) / 2
+	requests := 0
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+
+		if requests == 1 {
+			// Send half of the document, then drop the connection.
+			w.Header().Set("Content-Length", strconv.Itoa(len(testDocument)))
+			w.Write(testDocument[:half])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		testHeader(t, r, "Range", "bytes="+strconv.Itoa(half)+"-")
+		testHeader(t, r, "If-Range", `"v1"`)
+		http.ServeContent(w, r, "letter.pdf", time.Time{}, bytes.NewReader(testDocument))
+	})
+
+	w := new(writerAt)
+	got, _, err := client.Letters.DownloadDocumentTo(context.Background(), "b", w)
+	if err != nil {
+		t.Fatalf("Letters.DownloadDocumentTo returned error: %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Letters.DownloadDocumentTo made %d requests, want 2", requests)
+	}
+	if !bytes.Equal(w.buf, testDocument) {
+		t.Errorf("Letters.DownloadDocumentTo wrote %q, want %q", w.buf, testDocument)
+	}
+
+	checksum := sha256.Sum256(testDocument)
+	if !bytes.Equal(got.Checksum, checksum[:]) {
+		t.Errorf("Letters.DownloadDocumentTo returned checksum %x, want %x", got.Checksum, checksum)
+	}
+	if got.ContentLength != int64(len(testDocument)) {
+		t.Errorf("Letters.DownloadDocumentTo returned length %d, want %d", got.ContentLength, len(testDocument))
+	}
+}
+
+func TestLettersService_DownloadDocumentTo_rangeIgnored(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	requests := 0
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Length", strconv.Itoa(len(testDocument)))
+
+		if requests == 1 {
+			w.Write(testDocument[:10])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		// Respond with the whole document, ignoring the Range header.
+		w.Write(testDocument)
+	})
+
+	w := new(writerAt)
+	got, _, err := client.Letters.DownloadDocumentTo(context.Background(), "b", w)
+	if err != nil {
+		t.Fatalf("Letters.DownloadDocumentTo returned error: %v", err)
+	}
+
+	if !bytes.Equal(w.buf, testDocument) {
+		t.Errorf("Letters.DownloadDocumentTo wrote %q, want %q", w.buf, testDocument)
+	}
+
+	checksum := sha256.Sum256(testDocument)
+	if !bytes.Equal(got.Checksum, checksum[:]) {
+		t.Errorf("Letters.DownloadDocumentTo returned checksum %x, want %x", got.Checksum, checksum)
+	}
+}
+
+func TestLettersService_DownloadDocumentTo_restartTruncates(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	replaced := []byte("%PDF-1.4 replaced\n")
+	requests := 0
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(testDocument)))
+			w.Write(testDocument[:100])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		// The document has changed, respond with the new one.
+		w.Write(replaced)
+	})
+
+	w := new(writerAt)
+	if _, _, err := client.Letters.DownloadDocumentTo(context.Background(), "b", w); err != nil {
+		t.Fatalf("Letters.DownloadDocumentTo returned error: %v", err)
+	}
+
+	if !bytes.Equal(w.buf, replaced) {
+		t.Errorf("Letters.DownloadDocumentTo wrote %q, want %q", w.buf, replaced)
+	}
+
+	requests = 0
+	fw := new(fixedWriterAt)
+	if _, _, err := client.Letters.DownloadDocumentTo(context.Background(), "b", fw); err == nil {
+		t.Error("Letters.DownloadDocumentTo returned no error restarting into a writer that cannot be truncated")
+	}
+}
+
+func TestLettersService_DownloadDocumentTo_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"not_found","message":"Letter not found"}}`))
+	})
+
+	_, resp, err := client.Letters.DownloadDocumentTo(context.Background(), "b", new(writerAt))
+
+	var nfErr *NotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Errorf("Letters.DownloadDocumentTo returned %v, want a *NotFoundError", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Letters.DownloadDocumentTo returned response %v, want a 404 response", resp)
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		header      string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes 100-199/*", 100, -1, true},
+		{"bytes */200", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		r := &http.Response{StatusCode: http.StatusPartialContent, Header: http.Header{"Content-Range": {tt.header}}}
+		start, size, ok := contentRange(r)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("contentRange(%q) = %d, %d, %t, want %d, %d, %t", tt.header, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
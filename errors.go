@@ -1,16 +1,19 @@
 package gocancel
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // Error is the response returned when a call is unsuccessful.
 type Error struct {
 	Response *http.Response // HTTP response that caused this error
 
-	Code    string `json:"code"`    // error code
-	Message string `json:"message"` // error message
+	Code    string        `json:"code"`             // error code
+	Message string        `json:"message"`          // error message
+	Errors  []*FieldError `json:"errors,omitempty"` // per field validation errors
 }
 
 func (e *Error) Error() string {
@@ -22,7 +25,7 @@ func (e *Error) Error() string {
 // Is returns whether the provided error equals this error.
 func (r *Error) Is(target error) bool {
 	v, ok := target.(*Error)
-	if !ok {
+	if !ok || r == nil || v == nil {
 		return false
 	}
 
@@ -38,3 +41,193 @@ func (r *Error) Is(target error) bool {
 type errorRoot struct {
 	Error *Error `json:"error,omitempty"`
 }
+
+// FieldError describes why a single field of a request was rejected. Field
+// refers to the JSON name of the field, e.g. "organization_id" or
+// "parameters.name" for a LetterRequest.
+type FieldError struct {
+	Field   string `json:"field"`   // name of the rejected field
+	Code    string `json:"code"`    // validation error code
+	Message string `json:"message"` // validation error message
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Field, e.Message)
+}
+
+// FieldErrors is a list of field errors, as returned by client-side
+// validation such as ValidateLetterRequest.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Field returns the errors of the request field with the given JSON name.
+func (e FieldErrors) Field(name string) []*FieldError {
+	var errs []*FieldError
+	for _, fe := range e {
+		if fe.Field == name {
+			errs = append(errs, fe)
+		}
+	}
+	return errs
+}
+
+// AuthenticationError occurs when the API responds with 401 Unauthorized,
+// e.g. because of missing or expired credentials.
+type AuthenticationError struct {
+	Err *Error // underlying API error
+}
+
+func (e *AuthenticationError) Error() string { return apiErrorString(e.Err, "authentication error") }
+
+// Unwrap returns the underlying API error.
+func (e *AuthenticationError) Unwrap() error { return e.Err }
+
+// Is returns whether the provided error equals this error. A target without
+// an underlying API error, such as &AuthenticationError{}, matches any
+// AuthenticationError.
+func (e *AuthenticationError) Is(target error) bool {
+	v, ok := target.(*AuthenticationError)
+	return ok && (v.Err == nil || e.Err.Is(v.Err))
+}
+
+// PermissionError occurs when the API responds with 403 Forbidden, e.g.
+// because the credentials lack the required scopes.
+type PermissionError struct {
+	Err *Error // underlying API error
+}
+
+func (e *PermissionError) Error() string { return apiErrorString(e.Err, "permission error") }
+
+// Unwrap returns the underlying API error.
+func (e *PermissionError) Unwrap() error { return e.Err }
+
+// Is returns whether the provided error equals this error.
+func (e *PermissionError) Is(target error) bool {
+	v, ok := target.(*PermissionError)
+	return ok && (v.Err == nil || e.Err.Is(v.Err))
+}
+
+// NotFoundError occurs when the API responds with 404 Not Found.
+type NotFoundError struct {
+	Err *Error // underlying API error
+}
+
+func (e *NotFoundError) Error() string { return apiErrorString(e.Err, "not found") }
+
+// Unwrap returns the underlying API error.
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// Is returns whether the provided error equals this error.
+func (e *NotFoundError) Is(target error) bool {
+	v, ok := target.(*NotFoundError)
+	return ok && (v.Err == nil || e.Err.Is(v.Err))
+}
+
+// ValidationError occurs when the API rejects the request body, either with
+// 422 Unprocessable Entity or with 400 Bad Request listing field errors.
+type ValidationError struct {
+	Err *Error // underlying API error
+}
+
+func (e *ValidationError) Error() string { return apiErrorString(e.Err, "validation error") }
+
+// Unwrap returns the underlying API error.
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Is returns whether the provided error equals this error.
+func (e *ValidationError) Is(target error) bool {
+	v, ok := target.(*ValidationError)
+	return ok && (v.Err == nil || e.Err.Is(v.Err))
+}
+
+// Fields returns the errors of the individual request fields.
+func (e *ValidationError) Fields() []*FieldError {
+	return e.Err.Errors
+}
+
+// Field returns the errors of the request field with the given JSON name.
+func (e *ValidationError) Field(name string) []*FieldError {
+	return FieldErrors(e.Err.Errors).Field(name)
+}
+
+// ServerError occurs when the API responds with a 5xx status code.
+type ServerError struct {
+	Err *Error // underlying API error
+}
+
+func (e *ServerError) Error() string { return apiErrorString(e.Err, "server error") }
+
+// Unwrap returns the underlying API error.
+func (e *ServerError) Unwrap() error { return e.Err }
+
+// Is returns whether the provided error equals this error.
+func (e *ServerError) Is(target error) bool {
+	v, ok := target.(*ServerError)
+	return ok && (v.Err == nil || e.Err.Is(v.Err))
+}
+
+// apiErrorString returns the message of the API error wrapped by a typed
+// error, or kind if there is none.
+func apiErrorString(e *Error, kind string) string {
+	if e == nil {
+		return "gocancel: " + kind
+	}
+	return e.Error()
+}
+
+// typedError wraps e in the error type matching its HTTP status code. e is
+// returned as is if there is no specific type for the status code.
+func typedError(e *Error) error {
+	switch c := e.Response.StatusCode; {
+	case c == http.StatusUnauthorized:
+		return &AuthenticationError{Err: e}
+	case c == http.StatusForbidden:
+		return &PermissionError{Err: e}
+	case c == http.StatusNotFound:
+		return &NotFoundError{Err: e}
+	case c == http.StatusUnprocessableEntity,
+		c == http.StatusBadRequest && len(e.Errors) > 0:
+		return &ValidationError{Err: e}
+	case c == http.StatusTooManyRequests:
+		rate, _ := parseRate(e.Response)
+		return &RateLimitError{Rate: rate, Err: e}
+	case c >= 500 && c <= 599:
+		return &ServerError{Err: e}
+	}
+
+	return e
+}
+
+// IsRetryable reports whether the request that caused err may succeed when
+// sent again, either because the API was rate limited or temporarily
+// unavailable, or because of a transient network error. Note that only
+// idempotent requests can safely be retried.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rerr *RateLimitError
+	if errors.As(err, &rerr) {
+		return true
+	}
+
+	var serr *ServerError
+	if errors.As(err, &serr) {
+		return retryableStatus(serr.Err.Response.StatusCode)
+	}
+
+	var aerr *Error
+	if errors.As(err, &aerr) {
+		return false
+	}
+
+	return retryableError(err)
+}
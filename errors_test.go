@@ -1,8 +1,16 @@
 package gocancel
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -61,3 +69,195 @@ func TestError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckResponse_typedErrors(t *testing.T) {
+	testcases := map[string]struct {
+		status int
+		body   string
+		target interface{}
+	}{
+		"unauthorized": {
+			status: http.StatusUnauthorized,
+			target: new(*AuthenticationError),
+		},
+		"forbidden": {
+			status: http.StatusForbidden,
+			target: new(*PermissionError),
+		},
+		"not found": {
+			status: http.StatusNotFound,
+			target: new(*NotFoundError),
+		},
+		"unprocessable entity": {
+			status: http.StatusUnprocessableEntity,
+			target: new(*ValidationError),
+		},
+		"bad request with field errors": {
+			status: http.StatusBadRequest,
+			body:   `{"error": {"code": "invalid", "message": "m", "errors": [{"field": "locale", "code": "invalid", "message": "is invalid"}]}}`,
+			target: new(*ValidationError),
+		},
+		"too many requests": {
+			status: http.StatusTooManyRequests,
+			target: new(*RateLimitError),
+		},
+		"internal server error": {
+			status: http.StatusInternalServerError,
+			target: new(*ServerError),
+		},
+		"service unavailable": {
+			status: http.StatusServiceUnavailable,
+			target: new(*ServerError),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			body := tc.body
+			if body == "" {
+				body = `{"error": {"code": "c", "message": "m"}}`
+			}
+
+			res := &http.Response{
+				Request:    &http.Request{},
+				StatusCode: tc.status,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}
+			err := CheckResponse(res)
+
+			if !errors.As(err, tc.target) {
+				t.Fatalf("CheckResponse returned %T, want %T", err, reflect.ValueOf(tc.target).Elem().Interface())
+			}
+
+			var aerr *Error
+			if !errors.As(err, &aerr) {
+				t.Fatalf("CheckResponse returned %T which does not unwrap to *Error", err)
+			}
+			if aerr.Response != res {
+				t.Errorf("Error.Response = %v, want %v", aerr.Response, res)
+			}
+		})
+	}
+}
+
+func TestCheckResponse_badRequest(t *testing.T) {
+	res := &http.Response{
+		Request:    &http.Request{},
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"error": {"code": "c", "message": "m"}}`)),
+	}
+
+	if _, ok := CheckResponse(res).(*Error); !ok {
+		t.Errorf("CheckResponse returned %T, want *Error", CheckResponse(res))
+	}
+}
+
+func TestValidationError_Field(t *testing.T) {
+	err := &ValidationError{
+		Err: &Error{
+			Response: &http.Response{StatusCode: http.StatusUnprocessableEntity},
+			Errors: []*FieldError{
+				{Field: "organization_id", Code: "blank", Message: "can't be blank"},
+				{Field: "parameters.name", Code: "blank", Message: "can't be blank"},
+			},
+		},
+	}
+
+	if got := err.Fields(); len(got) != 2 {
+		t.Errorf("ValidationError.Fields returned %d errors, want 2", len(got))
+	}
+
+	got := err.Field("parameters.name")
+	if len(got) != 1 || got[0].Error() != "parameters.name: can't be blank" {
+		t.Errorf("ValidationError.Field returned %v, want the parameters.name error", got)
+	}
+
+	if got := err.Field("locale"); got != nil {
+		t.Errorf("ValidationError.Field returned %v, want nil", got)
+	}
+}
+
+func TestTypedError_Is(t *testing.T) {
+	e := &Error{Response: &http.Response{StatusCode: http.StatusNotFound}, Code: "c", Message: "m"}
+	err := &NotFoundError{Err: e}
+
+	if !errors.Is(err, &NotFoundError{Err: &Error{Response: &http.Response{StatusCode: http.StatusNotFound}, Code: "c", Message: "m"}}) {
+		t.Errorf("NotFoundError is not equal to an identical NotFoundError")
+	}
+	if errors.Is(err, &ServerError{Err: e}) {
+		t.Errorf("NotFoundError is equal to a ServerError")
+	}
+	if !errors.Is(err, e) {
+		t.Errorf("NotFoundError is not equal to its underlying *Error")
+	}
+}
+
+func TestTypedError_IsZeroValue(t *testing.T) {
+	e := &Error{Response: &http.Response{StatusCode: http.StatusNotFound}, Code: "c", Message: "m"}
+
+	tests := []struct {
+		err, target error
+	}{
+		{&AuthenticationError{Err: e}, &AuthenticationError{}},
+		{&PermissionError{Err: e}, &PermissionError{}},
+		{&NotFoundError{Err: e}, &NotFoundError{}},
+		{&ValidationError{Err: e}, &ValidationError{}},
+		{&RateLimitError{Rate: Rate{Limit: 60}, Err: e}, &RateLimitError{}},
+		{&ServerError{Err: e}, &ServerError{}},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.target) {
+			t.Errorf("errors.Is(%T, zero value) = false, want true", tt.err)
+		}
+		if errors.Is(tt.err, (*Error)(nil)) {
+			t.Errorf("errors.Is(%T, nil *Error) = true, want false", tt.err)
+		}
+		if tt.target.Error() == "" {
+			t.Errorf("Zero value %T has an empty error message", tt.target)
+		}
+	}
+
+	if errors.Is(&NotFoundError{Err: e}, &ServerError{}) {
+		t.Errorf("NotFoundError is equal to a zero ServerError")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	apiError := func(status int) *Error {
+		return &Error{Response: &http.Response{StatusCode: status}}
+	}
+
+	testcases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil":                   {err: nil, want: false},
+		"rate limited":          {err: &RateLimitError{Err: apiError(http.StatusTooManyRequests)}, want: true},
+		"service unavailable":   {err: &ServerError{Err: apiError(http.StatusServiceUnavailable)}, want: true},
+		"not implemented":       {err: &ServerError{Err: apiError(http.StatusNotImplemented)}, want: false},
+		"not found":             {err: &NotFoundError{Err: apiError(http.StatusNotFound)}, want: false},
+		"plain api error":       {err: apiError(http.StatusBadRequest), want: false},
+		"wrapped server error":  {err: fmt.Errorf("list: %w", &ServerError{Err: apiError(http.StatusBadGateway)}), want: true},
+		"unexpected eof":        {err: &url.Error{Op: "Get", URL: "/", Err: io.ErrUnexpectedEOF}, want: true},
+		"context canceled":      {err: context.Canceled, want: false},
+		"deadline exceeded":     {err: context.DeadlineExceeded, want: false},
+		"unrelated error":       {err: errors.New("boom"), want: false},
+		"timeout network error": {err: &net.OpError{Op: "dial", Err: timeoutError{}}, want: true},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsRetryable(tc.err); got != tc.want {
+				t.Errorf("IsRetryable(%v) = %t, want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -12,6 +12,8 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-querystring/query"
 	"golang.org/x/oauth2"
@@ -59,6 +61,31 @@ type Client struct {
 
 	// Optional extra HTTP headers to set on every request to the API.
 	headers map[string]string
+
+	// Optional policy for retrying failed requests, nil disables retries.
+	retryPolicy *RetryPolicy
+
+	// Optional sanitizer for uploaded proof of ID images.
+	imageSanitizer *ImageSanitizer
+
+	// Optional locales to filter List calls by when no locales are given.
+	preferredLocales []string
+
+	// Optional middleware wrapping every API call, outermost first.
+	middleware []Middleware
+
+	// Optional logger of every attempt, and the redactor of logged bodies.
+	logger   Logger
+	redactor Redactor
+
+	// Optional instrumentation of every API call.
+	tracer     Tracer
+	meter      Meter
+	propagator Propagator
+
+	rateMu        sync.Mutex
+	rate          Rate // Rate limit as of the most recent API response.
+	rateLimitWait bool // Wait for the rate limit to reset when exhausted.
 }
 
 type service struct {
@@ -222,12 +249,33 @@ type Response struct {
 	// For APIs that support cursor pagination, the metadata field is populated with the
 	// cursor values for paginating through a list of items.
 	Metadata *Metadata
+
+	// Attempts is the number of attempts it took to obtain this response,
+	// including the initial request.
+	Attempts int
+
+	// Rate is the rate limit as reported by this response.
+	Rate Rate
+
+	// IdempotencyKey is the Idempotency-Key the request was sent with, if
+	// any. See WithIdempotencyKey.
+	IdempotencyKey string
+
+	// IdempotentReplayed reports whether the API replayed the response to an
+	// earlier request with the same idempotency key, rather than performing
+	// the request again.
+	IdempotentReplayed bool
 }
 
 // newResponse creates a new Response for the provided http.Response.
 // r must not be nil.
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
+	response.Rate, _ = parseRate(r)
+	if r.Request != nil {
+		response.IdempotencyKey = r.Request.Header.Get(headerIdempotencyKey)
+	}
+	response.IdempotentReplayed = idempotentReplayed(r)
 	// response.populateMetadataValues()
 	return response
 }
@@ -240,6 +288,11 @@ func newResponse(r *http.Response) *Response {
 // or API Error occurs, the error will contain more information. Otherwise you
 // are supposed to read and close the response's Body.
 //
+// If a retry policy is configured, failed attempts are retried according to
+// that policy before a response or error is returned. The request passes
+// through the middleware of the client, see WithMiddleware, and is traced and
+// measured when a Tracer or Meter is configured.
+//
 // The provided ctx must be non-nil, if it is nil an error is returned. If it is
 // canceled or times out, ctx.Err() will be returned.
 func (c *Client) BareDo(ctx context.Context, req *http.Request) (*Response, error) {
@@ -248,26 +301,85 @@ func (c *Client) BareDo(ctx context.Context, req *http.Request) (*Response, erro
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.client.Do(req)
+	req, err := c.setIdempotencyKey(ctx, req)
 	if err != nil {
-		// If we got an error, and the context has been canceled,
-		// the context's error is probably more useful.
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
 		return nil, err
 	}
 
-	response := newResponse(resp)
+	h := Handler(c.send)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
 
-	err = CheckResponse(resp)
-	if err != nil {
-		defer resp.Body.Close()
+	return c.instrument(req, h)
+}
+
+// send sends req, retrying failed attempts according to the retry policy.
+func (c *Client) send(req *http.Request) (*Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.waitForRate(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.client.Do(req)
+		c.logAttempt(req, resp, err, attempt, time.Since(start))
+		if err != nil {
+			// If we got an error, and the context has been canceled,
+			// the context's error is probably more useful.
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+
+			if retryableError(err) && c.retryPolicy.canRetry(req, attempt) {
+				if err := sleep(ctx, c.retryPolicy.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			return nil, err
+		}
+
+		if rate, ok := parseRate(resp); ok {
+			c.updateRate(rate)
+		}
+
+		if retryableStatus(resp.StatusCode) && c.retryPolicy.canRetry(req, attempt) {
+			wait := c.retryPolicy.backoff(attempt)
+			retryAfter, ok := retryDelay(resp)
+			if ok {
+				wait = retryAfter
+			}
+
+			if !ok || retryAfter <= c.retryPolicy.MaxBackoff {
+				drainBody(resp.Body)
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		response := newResponse(resp)
+		response.Attempts = attempt
+
+		err = CheckResponse(resp)
+		if err != nil {
+			defer resp.Body.Close()
+		}
+		return response, err
 	}
-	return response, err
 }
 
 // Do sends an API request and returns the API response. The API response is
@@ -320,7 +432,9 @@ func compareHttpResponse(r1, r2 *http.Response) bool {
 // present. A response is considered an error if it has a status code outside
 // the 200 range.
 // API error responses are expected to have response
-// body, and a JSON response body that maps to Error.
+// body, and a JSON response body that maps to Error. Depending on the status
+// code, the *Error is wrapped in a more specific type, such as *NotFoundError
+// or *RateLimitError, which can be inspected using errors.As.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
@@ -339,7 +453,7 @@ func CheckResponse(r *http.Response) error {
 	}
 
 	e.Response = r
-	return e
+	return typedError(e)
 }
 
 // Bool is a helper routine that allocates a new bool value
@@ -361,3 +475,13 @@ func Int64(v int64) *int64 { return &v }
 // String is a helper routine that allocates a new string value
 // to store v and returns a pointer to it.
 func String(v string) *string { return &v }
+
+// Strings is a helper routine that allocates a new []string value
+// to store v and returns a pointer to it. Strings() returns a pointer
+// to an empty list.
+func Strings(v ...string) *[]string {
+	if v == nil {
+		v = []string{}
+	}
+	return &v
+}
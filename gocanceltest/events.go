@@ -0,0 +1,189 @@
+package gocanceltest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+	"github.com/gocancel/gocancel-go/webhooks"
+)
+
+// Delivery records the delivery of a webhook event to a webhook.
+type Delivery struct {
+	Webhook    string          // ID of the webhook
+	URL        string          // URL the event was delivered to
+	Event      *webhooks.Event // delivered event
+	StatusCode int             // status code of the response, if any
+	Err        error           // error sending the event, if any
+}
+
+// Deliveries returns the webhook deliveries made so far, in order. Events are
+// delivered in the background, so call Flush first to include the deliveries
+// of all events sent so far.
+func (s *Server) Deliveries() []*Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Delivery(nil), s.deliveries...)
+}
+
+// SetLetterState changes the state of a letter, as the GoCancel API does while
+// processing it, and sends the matching letter event. Changing the state to
+// "drafted", "sent" or "failed" sends a letter.drafted, letter.sent or
+// letter.failed event, any other state sends a letter.updated event.
+func (s *Server) SetLetterState(letter string, state gocancel.LetterState) error {
+	s.mu.Lock()
+	l, ok := s.letters.get(letter).(*gocancel.Letter)
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("gocanceltest: no letter %q", letter)
+	}
+
+	// Replace rather than modify the letter, responses of concurrent requests
+	// may still refer to the old one.
+	c := *l
+	c.State = &state
+	c.UpdatedAt = &gocancel.Timestamp{Time: s.now()}
+	s.letters.put(letter, &c)
+
+	t := webhooks.EventLetterUpdated
+	switch state {
+	case gocancel.LetterStateDrafted:
+		t = webhooks.EventLetterDrafted
+	case gocancel.LetterStateSent:
+		t = webhooks.EventLetterSent
+	case gocancel.LetterStateFailed:
+		t = webhooks.EventLetterFailed
+	}
+	e := s.event(t, c.Locale, &c)
+	s.mu.Unlock()
+
+	s.SendEvent(e)
+	return nil
+}
+
+// SendEvent delivers e to every active webhook subscribed to its type and
+// locale, signed with the secret of the webhook. Events are delivered in the
+// background, in the order they are sent, and recorded, see Deliveries.
+func (s *Server) SendEvent(e *webhooks.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	targets := s.webhooks.filter(func(v interface{}) bool {
+		w := v.(*gocancel.Webhook)
+		return w.Active != nil && *w.Active && w.Url != nil &&
+			subscribed(w.Events, string(e.Type)) && subscribed(w.Locales, e.Locale)
+	})
+	for _, v := range targets {
+		s.enqueue(v.(*gocancel.Webhook), e)
+	}
+}
+
+// Flush waits until all events sent so far have been delivered.
+func (s *Server) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.delivering {
+		s.idle.Wait()
+	}
+}
+
+// pendingDelivery is an event waiting to be delivered to a webhook.
+type pendingDelivery struct {
+	webhook *gocancel.Webhook
+	event   *webhooks.Event
+}
+
+// enqueue queues the delivery of e to w, starting a delivery goroutine if none
+// is running. The caller must hold s.mu.
+func (s *Server) enqueue(w *gocancel.Webhook, e *webhooks.Event) {
+	s.queue = append(s.queue, pendingDelivery{w, e})
+	if !s.delivering {
+		s.delivering = true
+		go s.deliverQueued()
+	}
+}
+
+// deliverQueued delivers the queued events one by one until the queue is
+// empty.
+func (s *Server) deliverQueued() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.delivering = false
+			s.idle.Broadcast()
+			s.mu.Unlock()
+			return
+		}
+		d := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		s.deliver(d.webhook, d.event)
+	}
+}
+
+// defaultWebhookClient delivers webhooks unless Server.WebhookClient is set.
+// Its timeout keeps a hanging receiver from stalling deliveries forever.
+var defaultWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// rolledSecret is the previous secret of a webhook, which remains valid for
+// a while after rolling the secret.
+type rolledSecret struct {
+	secret  string
+	expires time.Time
+}
+
+// deliver sends e to w and records the delivery.
+func (s *Server) deliver(w *gocancel.Webhook, e *webhooks.Event) {
+	if w.Url == nil {
+		return
+	}
+
+	secrets := []string{*w.Secret}
+
+	s.mu.Lock()
+	if rs, ok := s.rolledSecrets[*w.ID]; ok && s.now().Before(rs.expires) {
+		secrets = append(secrets, rs.secret)
+	}
+	s.mu.Unlock()
+
+	client := s.WebhookClient
+	if client == nil {
+		client = defaultWebhookClient
+	}
+
+	d := &Delivery{Webhook: *w.ID, URL: *w.Url, Event: e}
+
+	req, err := webhooks.NewSignedRequest(*w.Url, e, secrets...)
+	if err == nil {
+		resp, derr := client.Do(req)
+		if derr == nil {
+			d.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+		err = derr
+	}
+	d.Err = err
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, d)
+	s.mu.Unlock()
+}
+
+// subscribed reports whether a webhook subscribed to the given values, e.g.
+// event types, receives v. An empty subscription receives everything.
+func subscribed(values []*string, v string) bool {
+	if len(values) == 0 || v == "" {
+		return true
+	}
+
+	for _, value := range values {
+		if value != nil && *value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package gocanceltest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+	"github.com/gocancel/gocancel-go/webhooks"
+)
+
+// receiver records the letter events received by a webhooks.Handler.
+type receiver struct {
+	mu     sync.Mutex
+	events []webhooks.EventType
+}
+
+func (rc *receiver) record(ctx context.Context, e *webhooks.Event, letter *gocancel.Letter) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.events = append(rc.events, e.Type)
+	return nil
+}
+
+func (rc *receiver) received() []webhooks.EventType {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return append([]webhooks.EventType(nil), rc.events...)
+}
+
+func newReceiver(t *testing.T, secret string) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rc := new(receiver)
+	h := webhooks.NewHandler(secret)
+	for _, et := range []webhooks.EventType{
+		webhooks.EventLetterCreated,
+		webhooks.EventLetterUpdated,
+		webhooks.EventLetterDeleted,
+		webhooks.EventLetterDrafted,
+		webhooks.EventLetterSent,
+		webhooks.EventLetterFailed,
+	} {
+		h.OnLetterEvent(et, rc.record)
+	}
+
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	return rc, ts
+}
+
+func TestServer_webhooks(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	rc, ts := newReceiver(t, "secret")
+	s.AddWebhook(&gocancel.Webhook{Url: gocancel.String(ts.URL), Secret: gocancel.String("secret")})
+
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme")})
+	letter, _, err := client.Letters.Create(ctx, &gocancel.LetterRequest{OrganizationID: *org.ID})
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+
+	if err := s.SetLetterState(*letter.ID, gocancel.LetterStateSent); err != nil {
+		t.Fatalf("SetLetterState returned error: %v", err)
+	}
+
+	if _, err := client.Letters.Delete(ctx, *letter.ID); err != nil {
+		t.Fatalf("Letters.Delete returned error: %v", err)
+	}
+
+	want := []webhooks.EventType{
+		webhooks.EventLetterCreated,
+		webhooks.EventLetterSent,
+		webhooks.EventLetterDeleted,
+	}
+	s.Flush()
+	if got := rc.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Receiver got events %v, want %v", got, want)
+	}
+
+	for _, d := range s.Deliveries() {
+		if d.Err != nil || d.StatusCode != http.StatusOK {
+			t.Errorf("Delivery of %s failed: %d %v", d.Event.Type, d.StatusCode, d.Err)
+		}
+	}
+}
+
+func TestServer_webhooksSlowReceiver(t *testing.T) {
+	s, client := newTestServer(t)
+	s.WebhookClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+	s.AddWebhook(&gocancel.Webhook{Url: gocancel.String(ts.URL)})
+
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme")})
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := client.Letters.Create(context.Background(), &gocancel.LetterRequest{OrganizationID: *org.ID})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Letters.Create returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Letters.Create blocked on the webhook delivery")
+	}
+
+	s.Flush()
+	deliveries := s.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].Err == nil {
+		t.Errorf("Deliveries returned %v, want a single timed out delivery", deliveries)
+	}
+}
+
+func TestServer_webhookSubscriptions(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rc, ts := newReceiver(t, "secret")
+	s.AddWebhook(&gocancel.Webhook{
+		Url:    gocancel.String(ts.URL),
+		Secret: gocancel.String("secret"),
+		Events: []*string{gocancel.String(string(webhooks.EventLetterFailed))},
+	})
+	s.AddWebhook(&gocancel.Webhook{
+		Url:    gocancel.String(ts.URL),
+		Secret: gocancel.String("secret"),
+		Active: gocancel.Bool(false),
+	})
+
+	letter := s.AddLetter(&gocancel.Letter{})
+	_ = s.SetLetterState(*letter.ID, gocancel.LetterStateSent)
+	_ = s.SetLetterState(*letter.ID, gocancel.LetterStateFailed)
+
+	want := []webhooks.EventType{webhooks.EventLetterFailed}
+	s.Flush()
+	if got := rc.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Receiver got events %v, want %v", got, want)
+	}
+}
+
+func TestServer_webhookManagement(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	wh, _, err := client.Webhooks.Create(ctx, &gocancel.WebhookRequest{
+		URL:    "https://example.com",
+		Events: gocancel.Strings(string(webhooks.EventLetterFailed)),
+	})
+	if err != nil {
+		t.Fatalf("Webhooks.Create returned error: %v", err)
+	}
+	if wh.Secret == nil || !*wh.Active || len(wh.Events) != 1 {
+		t.Errorf("Webhooks.Create returned %+v, want an active webhook with a secret and an event", wh)
+	}
+
+	// An empty list of events subscribes the webhook to all events.
+	wh, _, err = client.Webhooks.Update(ctx, *wh.ID, &gocancel.WebhookRequest{Events: gocancel.Strings()})
+	if err != nil {
+		t.Fatalf("Webhooks.Update returned error: %v", err)
+	}
+	if len(wh.Events) != 0 {
+		t.Errorf("Webhooks.Update returned events %v, want none", wh.Events)
+	}
+
+	rc, ts := newReceiver(t, *wh.Secret)
+	if _, _, err := client.Webhooks.Update(ctx, *wh.ID, &gocancel.WebhookRequest{URL: ts.URL}); err != nil {
+		t.Fatalf("Webhooks.Update returned error: %v", err)
+	}
+
+	// The previous secret remains valid, so deliveries keep being accepted
+	// by the receiver while the secret rolls.
+	rolled, _, err := client.Webhooks.RollSecret(ctx, *wh.ID, &gocancel.RollWebhookSecretRequest{ExpiresIn: 3600})
+	if err != nil {
+		t.Fatalf("Webhooks.RollSecret returned error: %v", err)
+	}
+	if *rolled.Secret == *wh.Secret {
+		t.Errorf("Webhooks.RollSecret did not change the secret")
+	}
+
+	if _, err := client.Webhooks.SendTestEvent(ctx, *wh.ID, &gocancel.SendWebhookTestEventRequest{Event: string(webhooks.EventLetterSent)}); err != nil {
+		t.Fatalf("Webhooks.SendTestEvent returned error: %v", err)
+	}
+
+	want := []webhooks.EventType{webhooks.EventLetterSent}
+	s.Flush()
+	if got := rc.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Receiver got events %v, want %v", got, want)
+	}
+
+	if _, err := client.Webhooks.Delete(ctx, *wh.ID); err != nil {
+		t.Fatalf("Webhooks.Delete returned error: %v", err)
+	}
+	if _, _, err := client.Webhooks.Get(ctx, *wh.ID); err == nil {
+		t.Errorf("Webhooks.Get returned no error for a deleted webhook")
+	}
+}
@@ -0,0 +1,712 @@
+package gocanceltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+	"github.com/gocancel/gocancel-go/webhooks"
+)
+
+// serveHTTP routes the API requests to their handlers.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if f := s.failure(r); f != nil {
+		for k, v := range f.Header {
+			w.Header()[k] = v
+		}
+		writeError(w, f.Status, f.Code, f.Message)
+		return
+	}
+
+	segs, ok := splitPath(r.URL.Path)
+	if !ok {
+		writeNotFound(w, r)
+		return
+	}
+
+	switch segs[0] {
+	case "accounts":
+		s.serveResource(w, r, segs, s.accounts, "account", "", nil)
+	case "categories":
+		s.serveResource(w, r, segs, s.categories, "category", "categories", func(v interface{}) bool {
+			return matches(r, "slug", v.(*gocancel.Category).Slug)
+		})
+	case "organizations":
+		s.serveOrganizations(w, r, segs)
+	case "proof_of_ids":
+		s.serveProofOfIDs(w, r, segs)
+	case "products":
+		s.serveResource(w, r, segs, s.products, "product", "", nil)
+	case "providers":
+		s.serveResource(w, r, segs, s.providers, "provider", "providers", nil)
+	case "letters":
+		s.serveLetters(w, r, segs)
+	case "webhooks":
+		s.serveWebhooks(w, r, segs)
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+// serveResource serves the read-only list and get endpoints of a collection.
+// An empty plural disables the list endpoint.
+func (s *Server) serveResource(w http.ResponseWriter, r *http.Request, segs []string, c *collection, singular, plural string, keep func(v interface{}) bool) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	switch {
+	case len(segs) == 1 && plural != "":
+		s.respondList(w, r, c, plural, keep)
+	case len(segs) == 2:
+		s.respondGet(w, r, c, singular, segs[1])
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+func (s *Server) serveOrganizations(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) < 3 {
+		s.serveResource(w, r, segs, s.organizations, "organization", "organizations", func(v interface{}) bool {
+			o := v.(*gocancel.Organization)
+			return matches(r, "category", o.CategoryID) && matches(r, "slug", o.Slug)
+		})
+		return
+	}
+
+	if segs[2] != "products" || len(segs) > 4 {
+		writeNotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.organizations.get(segs[1]).(*gocancel.Organization)
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w, r)
+		return
+	}
+
+	ofOrganization := func(v interface{}) bool {
+		p := v.(*gocancel.Product)
+		return p.OrganizationID != nil && *p.OrganizationID == segs[1]
+	}
+
+	if len(segs) == 3 {
+		s.respondList(w, r, s.products, "products", func(v interface{}) bool {
+			return ofOrganization(v) && matches(r, "slug", v.(*gocancel.Product).Slug)
+		})
+		return
+	}
+
+	s.mu.Lock()
+	p, ok := s.products.get(segs[3]).(*gocancel.Product)
+	s.mu.Unlock()
+	if !ok || !ofOrganization(p) {
+		writeNotFound(w, r)
+		return
+	}
+
+	s.respondGet(w, r, s.products, "product", segs[3])
+}
+
+func (s *Server) serveLetters(w http.ResponseWriter, r *http.Request, segs []string) {
+	switch {
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		created, err := parseTimeRange(r, "created_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		updated, err := parseTimeRange(r, "updated_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		q := r.URL.Query()
+		s.respondList(w, r, s.letters, "letters", func(v interface{}) bool {
+			l := v.(*gocancel.Letter)
+			return contains(q["states[]"], (*string)(l.State)) &&
+				contains(q["organization_ids[]"], l.OrganizationID) &&
+				contains(q["product_ids[]"], l.ProductID) &&
+				contains(q["provider_ids[]"], l.ProviderID) &&
+				matchesBool(r, "sandbox_mode", l.SandboxMode) &&
+				matchesMetadata(r, l.Metadata) &&
+				created.contains(l.CreatedAt) &&
+				updated.contains(l.UpdatedAt)
+		})
+	case len(segs) == 1 && r.Method == http.MethodPost:
+		s.createLetter(w, r)
+	case len(segs) == 1:
+		writeMethodNotAllowed(w, r)
+	case len(segs) == 2 && r.Method == http.MethodGet:
+		s.respondGet(w, r, s.letters, "letter", segs[1])
+	case len(segs) == 2 && r.Method == http.MethodPut:
+		s.updateLetter(w, r, segs[1])
+	case len(segs) == 2 && r.Method == http.MethodDelete:
+		s.deleteLetter(w, r, segs[1])
+	case len(segs) == 2:
+		writeMethodNotAllowed(w, r)
+	case len(segs) == 3 && segs[2] == "document" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		content, ok := s.documents[segs[1]]
+		if !ok && s.letters.get(segs[1]) != nil {
+			content, ok = []byte(fmt.Sprintf("Cancellation letter %s", segs[1])), true
+		}
+		s.mu.Unlock()
+		serveFile(w, r, segs[1]+".pdf", content, ok)
+	case len(segs) == 3 && segs[2] == "mark_as_drafted" && r.Method == http.MethodPost:
+		s.markLetterAsDrafted(w, r, segs[1])
+	case len(segs) == 4 && segs[2] == "proof_of_ids" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		content, ok := s.proofOfIDs[segs[1]+"/"+segs[3]]
+		s.mu.Unlock()
+		serveFile(w, r, segs[3], content, ok)
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+// createLetter creates a letter. A request repeating the Idempotency-Key of an
+// earlier one is answered with the letter created by the earlier request.
+func (s *Server) createLetter(w http.ResponseWriter, r *http.Request) {
+	req := new(gocancel.LetterRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+
+	s.mu.Lock()
+	if body, ok := s.idempotent[key]; ok && key != "" {
+		s.mu.Unlock()
+		w.Header().Set("Idempotent-Replayed", "true")
+		writeRaw(w, http.StatusCreated, body)
+		return
+	}
+
+	org, _ := s.organizations.get(req.OrganizationID).(*gocancel.Organization)
+	product, _ := s.products.get(req.ProductID).(*gocancel.Product)
+
+	var fields []*gocancel.FieldError
+	if org == nil {
+		fields = append(fields, &gocancel.FieldError{Field: "organization_id", Code: "not_found", Message: "does not exist"})
+	}
+	if req.ProductID != "" && product == nil {
+		fields = append(fields, &gocancel.FieldError{Field: "product_id", Code: "not_found", Message: "does not exist"})
+	}
+	if len(fields) > 0 {
+		s.mu.Unlock()
+		writeError(w, http.StatusUnprocessableEntity, "invalid_request", "Letter is invalid", fields...)
+		return
+	}
+
+	state := gocancel.LetterStateCreated
+	if req.Drafted {
+		state = gocancel.LetterStateDrafted
+	}
+
+	l := &gocancel.Letter{
+		AccountID:        s.account.ID,
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		State:            &state,
+		SandboxMode:      s.account.SandboxMode,
+		SandboxEmail:     s.account.SandboxEmail,
+		Email:            org.Email,
+		Fax:              org.Fax,
+		Address:          org.Address,
+	}
+	if product != nil {
+		l.ProductID = product.ID
+		l.ProductName = product.Name
+	}
+	applyLetterRequest(l, req)
+	s.stamp(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+	s.letters.put(*l.ID, l)
+	s.attachProofOfIDs(l)
+
+	body := marshal(map[string]interface{}{"letter": l})
+	if key != "" {
+		s.idempotent[key] = body
+	}
+	e := s.event(webhooks.EventLetterCreated, l.Locale, l)
+	s.mu.Unlock()
+
+	s.SendEvent(e)
+	writeRaw(w, http.StatusCreated, body)
+}
+
+func (s *Server) updateLetter(w http.ResponseWriter, r *http.Request, id string) {
+	req := new(gocancel.LetterRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	l, ok := s.letters.get(id).(*gocancel.Letter)
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w, r)
+		return
+	}
+
+	// Replace rather than modify the letter, responses of concurrent requests
+	// may still refer to the old one.
+	c := *l
+	applyLetterRequest(&c, req)
+	c.UpdatedAt = &gocancel.Timestamp{Time: s.now()}
+	s.letters.put(id, &c)
+	s.attachProofOfIDs(&c)
+
+	body := marshal(map[string]interface{}{"letter": &c})
+	e := s.event(webhooks.EventLetterUpdated, c.Locale, &c)
+	s.mu.Unlock()
+
+	s.SendEvent(e)
+	writeRaw(w, http.StatusOK, body)
+}
+
+func (s *Server) deleteLetter(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	l, ok := s.letters.get(id).(*gocancel.Letter)
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w, r)
+		return
+	}
+
+	s.letters.remove(id)
+	e := s.event(webhooks.EventLetterDeleted, l.Locale, l)
+	s.mu.Unlock()
+
+	s.SendEvent(e)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) markLetterAsDrafted(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	_, ok := s.letters.get(id).(*gocancel.Letter)
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w, r)
+		return
+	}
+
+	if err := s.SetLetterState(id, gocancel.LetterStateDrafted); err != nil {
+		writeNotFound(w, r)
+		return
+	}
+
+	s.respondGet(w, r, s.letters, "letter", id)
+}
+
+// attachProofOfIDs makes the uploaded proofs of ID referred to by l
+// downloadable through the letter.
+func (s *Server) attachProofOfIDs(l *gocancel.Letter) {
+	for _, id := range l.ProofOfIDs {
+		if content, ok := s.uploads[*id]; ok {
+			s.proofOfIDs[*l.ID+"/"+*id] = content
+		}
+	}
+}
+
+func (s *Server) serveProofOfIDs(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) != 1 {
+		writeNotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, gocancel.MaxProofOfIDSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if len(content) > gocancel.MaxProofOfIDSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "too_large", "Proof of ID is too large")
+		return
+	}
+
+	p := &gocancel.ProofOfID{
+		ID:          gocancel.String(newID()),
+		Filename:    gocancel.String(header.Filename),
+		ContentType: gocancel.String(header.Header.Get("Content-Type")),
+		Size:        gocancel.Int64(int64(len(content))),
+		CreatedAt:   &gocancel.Timestamp{Time: s.now()},
+	}
+
+	s.mu.Lock()
+	s.uploads[*p.ID] = content
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"proof_of_id": p})
+}
+
+// applyLetterRequest copies the fields set in req to l.
+func applyLetterRequest(l *gocancel.Letter, req *gocancel.LetterRequest) {
+	if req.ProviderID != "" {
+		l.ProviderID = gocancel.String(req.ProviderID)
+	}
+	if req.Locale != "" {
+		l.Locale = gocancel.String(req.Locale)
+	}
+	if req.Parameters != nil {
+		p := req.Parameters
+		l.Parameters = &p
+	}
+	if req.ProofOfIDs != nil {
+		l.ProofOfIDs = nil
+		for _, id := range req.ProofOfIDs {
+			l.ProofOfIDs = append(l.ProofOfIDs, gocancel.String(id))
+		}
+	}
+	if req.SignatureType != "" {
+		l.SignatureType = req.SignatureType.Ptr()
+	}
+	if req.SignatureData != "" {
+		l.SignatureData = gocancel.String(req.SignatureData)
+	}
+	if req.Metadata != nil {
+		m := req.Metadata
+		l.Metadata = &m
+	}
+}
+
+func (s *Server) serveWebhooks(w http.ResponseWriter, r *http.Request, segs []string) {
+	switch {
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		s.respondList(w, r, s.webhooks, "webhooks", func(v interface{}) bool {
+			return matchesBool(r, "active", v.(*gocancel.Webhook).Active)
+		})
+	case len(segs) == 1 && r.Method == http.MethodPost:
+		s.writeWebhook(w, r, "")
+	case len(segs) == 1:
+		writeMethodNotAllowed(w, r)
+	case len(segs) == 2 && r.Method == http.MethodGet:
+		s.respondGet(w, r, s.webhooks, "webhook", segs[1])
+	case len(segs) == 2 && r.Method == http.MethodPut:
+		s.writeWebhook(w, r, segs[1])
+	case len(segs) == 2 && r.Method == http.MethodDelete:
+		s.mu.Lock()
+		ok := s.webhooks.get(segs[1]) != nil
+		s.webhooks.remove(segs[1])
+		s.mu.Unlock()
+		if !ok {
+			writeNotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(segs) == 2:
+		writeMethodNotAllowed(w, r)
+	case len(segs) == 3 && segs[2] == "roll_secret" && r.Method == http.MethodPost:
+		s.rollWebhookSecret(w, r, segs[1])
+	case len(segs) == 3 && segs[2] == "test" && r.Method == http.MethodPost:
+		s.sendWebhookTestEvent(w, r, segs[1])
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+// writeWebhook creates a webhook if id is empty, and updates the webhook with
+// the given ID otherwise.
+func (s *Server) writeWebhook(w http.ResponseWriter, r *http.Request, id string) {
+	req := new(gocancel.WebhookRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+
+	if id == "" && req.URL == "" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_request", "Webhook is invalid",
+			&gocancel.FieldError{Field: "url", Code: "blank", Message: "can't be blank"})
+		return
+	}
+
+	wh := new(gocancel.Webhook)
+	status := http.StatusCreated
+
+	s.mu.Lock()
+	if id != "" {
+		old, ok := s.webhooks.get(id).(*gocancel.Webhook)
+		if !ok {
+			s.mu.Unlock()
+			writeNotFound(w, r)
+			return
+		}
+
+		*wh = *old
+		wh.UpdatedAt = &gocancel.Timestamp{Time: s.now()}
+		status = http.StatusOK
+	}
+
+	if req.URL != "" {
+		wh.Url = gocancel.String(req.URL)
+	}
+	if req.Events != nil {
+		wh.Events = stringPtrs(*req.Events)
+	}
+	if req.Locales != nil {
+		wh.Locales = stringPtrs(*req.Locales)
+	}
+	if req.Metadata != nil {
+		m := req.Metadata
+		wh.Metadata = &m
+	}
+	if req.Active != nil {
+		wh.Active = gocancel.Bool(*req.Active)
+	}
+	s.mu.Unlock()
+
+	if id == "" {
+		wh = s.AddWebhook(wh)
+	} else {
+		s.mu.Lock()
+		s.webhooks.put(id, wh)
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	body := marshal(map[string]interface{}{"webhook": wh})
+	s.mu.Unlock()
+
+	writeRaw(w, status, body)
+}
+
+func (s *Server) rollWebhookSecret(w http.ResponseWriter, r *http.Request, id string) {
+	req := new(gocancel.RollWebhookSecretRequest)
+	_ = json.NewDecoder(r.Body).Decode(req)
+
+	s.mu.Lock()
+	old, ok := s.webhooks.get(id).(*gocancel.Webhook)
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w, r)
+		return
+	}
+
+	wh := *old
+	wh.Secret = gocancel.String("wh_sig_" + newID())
+	wh.UpdatedAt = &gocancel.Timestamp{Time: s.now()}
+	s.webhooks.put(id, &wh)
+
+	if req.ExpiresIn > 0 {
+		s.rolledSecrets[id] = rolledSecret{
+			secret:  *old.Secret,
+			expires: s.now().Add(time.Duration(req.ExpiresIn) * time.Second),
+		}
+	} else {
+		delete(s.rolledSecrets, id)
+	}
+
+	body := marshal(map[string]interface{}{"webhook": &wh})
+	s.mu.Unlock()
+
+	writeRaw(w, http.StatusOK, body)
+}
+
+func (s *Server) sendWebhookTestEvent(w http.ResponseWriter, r *http.Request, id string) {
+	req := new(gocancel.SendWebhookTestEventRequest)
+	_ = json.NewDecoder(r.Body).Decode(req)
+	if req.Event == "" {
+		req.Event = string(webhooks.EventLetterCreated)
+	}
+
+	s.mu.Lock()
+	wh, ok := s.webhooks.get(id).(*gocancel.Webhook)
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w, r)
+		return
+	}
+
+	e := s.event(webhooks.EventType(req.Event), nil, &gocancel.Letter{
+		ID:        gocancel.String(newID()),
+		AccountID: s.account.ID,
+		State:     gocancel.LetterStateCreated.Ptr(),
+	})
+	s.enqueue(wh, e)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// respondList responds with the page of items in c for which keep returns
+// true, wrapped in an object under the given key.
+func (s *Server) respondList(w http.ResponseWriter, r *http.Request, c *collection, key string, keep func(v interface{}) bool) {
+	s.mu.Lock()
+	page, meta := paginate(r, c.filter(keep))
+	if page == nil {
+		page = []interface{}{}
+	}
+	body := marshal(map[string]interface{}{key: page, "metadata": meta})
+	s.mu.Unlock()
+
+	writeRaw(w, http.StatusOK, body)
+}
+
+// respondGet responds with the item in c with the given ID, wrapped in an
+// object under the given key.
+func (s *Server) respondGet(w http.ResponseWriter, r *http.Request, c *collection, key, id string) {
+	s.mu.Lock()
+	v := c.get(id)
+	var body []byte
+	if v != nil {
+		body = marshal(map[string]interface{}{key: v})
+	}
+	s.mu.Unlock()
+
+	if v == nil {
+		writeNotFound(w, r)
+		return
+	}
+
+	writeRaw(w, http.StatusOK, body)
+}
+
+// serveFile serves content as a downloadable file, supporting range requests.
+func serveFile(w http.ResponseWriter, r *http.Request, name string, content []byte, ok bool) {
+	if !ok {
+		writeNotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(name))
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// matches reports whether the query parameter key of r is absent or equal to
+// v.
+func matches(r *http.Request, key string, v *string) bool {
+	q := r.URL.Query()
+	if _, ok := q[key]; !ok {
+		return true
+	}
+
+	return v != nil && *v == q.Get(key)
+}
+
+// matchesBool reports whether the boolean query parameter key of r is absent
+// or equal to v.
+func matchesBool(r *http.Request, key string, v *bool) bool {
+	q := r.URL.Query()
+	if _, ok := q[key]; !ok {
+		return true
+	}
+
+	want, err := strconv.ParseBool(q.Get(key))
+	if err != nil {
+		return true
+	}
+
+	return (v != nil && *v) == want
+}
+
+// matchesMetadata reports whether m holds the value of every metadata[key]
+// query parameter of r.
+func matchesMetadata(r *http.Request, m *gocancel.AccountMetadata) bool {
+	for k, values := range r.URL.Query() {
+		if !strings.HasPrefix(k, "metadata[") || !strings.HasSuffix(k, "]") {
+			continue
+		}
+		if m == nil {
+			return false
+		}
+
+		v, ok := (*m)[k[len("metadata["):len(k)-1]]
+		if !ok || fmt.Sprint(v) != values[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// timeRange is a range of time parsed from the key[gte] and key[lte] query
+// parameters. A zero bound is not applied.
+type timeRange struct {
+	after, before time.Time
+}
+
+// parseTimeRange parses the time range query parameters key[gte] and key[lte]
+// of r.
+func parseTimeRange(r *http.Request, key string) (timeRange, error) {
+	var tr timeRange
+
+	q := r.URL.Query()
+	for param, bound := range map[string]*time.Time{"gte": &tr.after, "lte": &tr.before} {
+		v := q.Get(key + "[" + param + "]")
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return tr, fmt.Errorf("invalid %s[%s]: %v", key, param, err)
+		}
+		*bound = t
+	}
+
+	return tr, nil
+}
+
+// contains reports whether t lies within the range.
+func (tr timeRange) contains(t *gocancel.Timestamp) bool {
+	if tr.after.IsZero() && tr.before.IsZero() {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+
+	return !t.Time.Before(tr.after) && (tr.before.IsZero() || !t.Time.After(tr.before))
+}
+
+func stringPtrs(values []string) []*string {
+	ptrs := make([]*string, len(values))
+	for i := range values {
+		ptrs[i] = gocancel.String(values[i])
+	}
+	return ptrs
+}
+
+func marshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func writeRaw(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Method %s is not allowed", r.Method))
+}
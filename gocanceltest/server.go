@@ -0,0 +1,458 @@
+// Package gocanceltest provides a stateful in-memory fake of the GoCancel API
+// for testing code that uses the gocancel package.
+package gocanceltest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+	"github.com/gocancel/gocancel-go/webhooks"
+)
+
+const (
+	defaultLimit = 25
+	maxLimit     = 100
+)
+
+// Server is a fake GoCancel API server. It keeps its resources in memory, so
+// resources created through the API can be read back, and it sends signed
+// webhooks for letter events to the registered webhooks.
+//
+// Seed the server using the Add methods, then use the client returned by
+// Client to talk to it.
+type Server struct {
+	// URL is the base URL of the server, with a trailing slash.
+	URL string
+
+	// WebhookClient is the HTTP client used to deliver webhooks. Defaults
+	// to a client with a 10 second timeout.
+	WebhookClient *http.Client
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	account       *gocancel.Account
+	accounts      *collection
+	categories    *collection
+	organizations *collection
+	products      *collection
+	providers     *collection
+	letters       *collection
+	webhooks      *collection
+	documents     map[string][]byte
+	proofOfIDs    map[string][]byte // keyed by letter and proof of ID
+	uploads       map[string][]byte // uploaded proofs of ID, by ID
+	idempotent    map[string][]byte // created letters, by Idempotency-Key
+	failures      []*Failure
+	rolledSecrets map[string]rolledSecret
+	deliveries    []*Delivery
+	queue         []pendingDelivery
+	delivering    bool
+	idle          *sync.Cond // signalled when delivering becomes false
+	eventSeq      int
+	now           func() time.Time
+}
+
+// NewServer starts and returns a new Server with a single account. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		accounts:      newCollection(),
+		categories:    newCollection(),
+		organizations: newCollection(),
+		products:      newCollection(),
+		providers:     newCollection(),
+		letters:       newCollection(),
+		webhooks:      newCollection(),
+		documents:     make(map[string][]byte),
+		proofOfIDs:    make(map[string][]byte),
+		uploads:       make(map[string][]byte),
+		idempotent:    make(map[string][]byte),
+		rolledSecrets: make(map[string]rolledSecret),
+		now:           func() time.Time { return time.Now().UTC().Truncate(time.Second) },
+	}
+	s.idle = sync.NewCond(&s.mu)
+
+	s.account = s.AddAccount(&gocancel.Account{
+		Name:        gocancel.String("Test"),
+		SandboxMode: gocancel.Bool(true),
+	})
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/"
+
+	return s
+}
+
+// Close shuts down the server, after which it waits for pending webhook
+// deliveries to finish.
+func (s *Server) Close() {
+	s.server.Close()
+	s.Flush()
+}
+
+// Client returns a gocancel.Client configured to talk to the server.
+func (s *Server) Client(opts ...gocancel.ClientOpt) (*gocancel.Client, error) {
+	return gocancel.New(s.server.Client(), append([]gocancel.ClientOpt{gocancel.SetBaseURL(s.URL)}, opts...)...)
+}
+
+// Account returns the account owning the resources of the server.
+func (s *Server) Account() *gocancel.Account {
+	return s.account
+}
+
+// Failure describes an error response injected by InjectFailure.
+type Failure struct {
+	// Method and Path match the requests to fail, e.g. "POST" and
+	// "/api/v1/letters". An empty Method matches any method.
+	Method string
+	Path   string
+
+	// Status, Code and Message make up the error response.
+	Status  int
+	Code    string
+	Message string
+
+	// Header holds extra headers of the error response, e.g. Retry-After.
+	Header http.Header
+
+	// Times is the number of matching requests to fail. Defaults to one.
+	Times int
+}
+
+// InjectFailure makes the server respond to the next matching requests with
+// the described error instead of handling them.
+func (s *Server) InjectFailure(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.failures = append(s.failures, &f)
+}
+
+// failure returns the injected failure matching r, if any.
+func (s *Server) failure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if (f.Method == "" || f.Method == r.Method) && f.Path == r.URL.Path {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+			return f
+		}
+	}
+
+	return nil
+}
+
+// AddAccount adds an account to the server.
+func (s *Server) AddAccount(a *gocancel.Account) *gocancel.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	s.accounts.put(*a.ID, a)
+	return a
+}
+
+// AddCategory adds a category to the server.
+func (s *Server) AddCategory(c *gocancel.Category) *gocancel.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	s.categories.put(*c.ID, c)
+	return c
+}
+
+// AddOrganization adds an organization to the server.
+func (s *Server) AddOrganization(o *gocancel.Organization) *gocancel.Organization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	s.organizations.put(*o.ID, o)
+	return o
+}
+
+// AddProduct adds a product to the server. The product should refer to an
+// organization using its OrganizationID.
+func (s *Server) AddProduct(p *gocancel.Product) *gocancel.Product {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	s.products.put(*p.ID, p)
+	return p
+}
+
+// AddProvider adds a provider to the server.
+func (s *Server) AddProvider(p *gocancel.Provider) *gocancel.Provider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	s.providers.put(*p.ID, p)
+	return p
+}
+
+// AddLetter adds a letter to the server, without sending webhooks.
+func (s *Server) AddLetter(l *gocancel.Letter) *gocancel.Letter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+	if l.AccountID == nil {
+		l.AccountID = s.account.ID
+	}
+	if l.State == nil {
+		l.State = gocancel.LetterStateCreated.Ptr()
+	}
+	s.letters.put(*l.ID, l)
+	return l
+}
+
+// AddWebhook adds a webhook to the server. Letter events are delivered to the
+// URL of active webhooks that subscribe to them, signed with their secret. A
+// secret is generated if the webhook has none.
+func (s *Server) AddWebhook(w *gocancel.Webhook) *gocancel.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+	if w.AccountID == nil {
+		w.AccountID = s.account.ID
+	}
+	if w.Active == nil {
+		w.Active = gocancel.Bool(true)
+	}
+	if w.Secret == nil {
+		w.Secret = gocancel.String("wh_sig_" + newID())
+	}
+	s.webhooks.put(*w.ID, w)
+	return w
+}
+
+// SetDocument sets the document of a letter, as served by
+// LettersService.DownloadDocument.
+func (s *Server) SetDocument(letter string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.documents[letter] = content
+}
+
+// AddProofOfID adds a proof of ID to a letter, as served by
+// LettersService.DownloadProofOfID.
+func (s *Server) AddProofOfID(letter, proofOfID string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.proofOfIDs[letter+"/"+proofOfID] = content
+	if l, ok := s.letters.get(letter).(*gocancel.Letter); ok {
+		c := *l
+		c.ProofOfIDs = append(append([]*string(nil), l.ProofOfIDs...), gocancel.String(proofOfID))
+		s.letters.put(letter, &c)
+	}
+}
+
+// Letter returns a copy of the letter with the given ID, or nil if there is
+// no such letter.
+func (s *Server) Letter(id string) *gocancel.Letter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.letters.get(id).(*gocancel.Letter)
+	if !ok {
+		return nil
+	}
+
+	c := *l
+	return &c
+}
+
+// stamp assigns an ID and timestamps to a resource that lacks them.
+func (s *Server) stamp(id **string, createdAt, updatedAt **gocancel.Timestamp) {
+	if *id == nil {
+		*id = gocancel.String(newID())
+	}
+
+	now := &gocancel.Timestamp{Time: s.now()}
+	if *createdAt == nil {
+		*createdAt = now
+	}
+	if *updatedAt == nil {
+		*updatedAt = now
+	}
+}
+
+// collection holds resources of a single type in insertion order.
+type collection struct {
+	ids   []string
+	items map[string]interface{}
+}
+
+func newCollection() *collection {
+	return &collection{items: make(map[string]interface{})}
+}
+
+func (c *collection) put(id string, v interface{}) {
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = v
+}
+
+func (c *collection) get(id string) interface{} {
+	return c.items[id]
+}
+
+func (c *collection) remove(id string) {
+	if _, ok := c.items[id]; !ok {
+		return
+	}
+
+	delete(c.items, id)
+	for i, v := range c.ids {
+		if v == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// filter returns the items for which keep returns true, in insertion order.
+func (c *collection) filter(keep func(v interface{}) bool) []interface{} {
+	var items []interface{}
+	for _, id := range c.ids {
+		if v := c.items[id]; keep == nil || keep(v) {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+// paginate returns the page of items selected by the cursor and limit query
+// parameters of r, along with the cursors of the adjacent pages. Cursors are
+// offsets into items.
+func paginate(r *http.Request, items []interface{}) ([]interface{}, *gocancel.Metadata) {
+	q := r.URL.Query()
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset, _ := strconv.Atoi(q.Get("cursor"))
+	if offset < 0 || offset > len(items) {
+		offset = len(items)
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	meta := new(gocancel.Metadata)
+	if end < len(items) {
+		meta.NextCursor = strconv.Itoa(end)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		meta.PreviousCursor = strconv.Itoa(prev)
+	}
+
+	return items[offset:end], meta
+}
+
+// contains reports whether values contains v. An empty values contains
+// everything.
+func contains(values []string, v *string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+
+	for _, value := range values {
+		if value == *v {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string, fields ...*gocancel.FieldError) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": &gocancel.Error{Code: code, Message: message, Errors: fields},
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Cannot find %s", r.URL.Path))
+}
+
+// newID returns a random UUID.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// splitPath splits the path of an API request into its segments, without the
+// api/v1 prefix. It returns false for paths outside the API.
+func splitPath(path string) ([]string, bool) {
+	const prefix = "/api/v1/"
+	if !strings.HasPrefix(path, prefix) {
+		return nil, false
+	}
+
+	return strings.Split(strings.TrimSuffix(strings.TrimPrefix(path, prefix), "/"), "/"), true
+}
+
+// event returns a new webhook event of type t about the given data.
+func (s *Server) event(t webhooks.EventType, locale *string, data interface{}) *webhooks.Event {
+	s.eventSeq++
+
+	raw, _ := json.Marshal(data)
+	e := &webhooks.Event{
+		ID:        fmt.Sprintf("evt_%d", s.eventSeq),
+		Type:      t,
+		CreatedAt: gocancel.Timestamp{Time: s.now()},
+		AccountID: *s.account.ID,
+		Data:      raw,
+	}
+	if locale != nil {
+		e.Locale = *locale
+	}
+
+	return e
+}
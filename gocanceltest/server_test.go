@@ -0,0 +1,395 @@
+package gocanceltest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+func newTestServer(t *testing.T) (*Server, *gocancel.Client) {
+	t.Helper()
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	client, err := s.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %v", err)
+	}
+
+	return s, client
+}
+
+func TestServer_accounts(t *testing.T) {
+	s, client := newTestServer(t)
+
+	account, _, err := client.Accounts.Get(context.Background(), *s.Account().ID)
+	if err != nil {
+		t.Fatalf("Accounts.Get returned error: %v", err)
+	}
+
+	if *account.Name != "Test" {
+		t.Errorf("Accounts.Get returned name %q, want %q", *account.Name, "Test")
+	}
+}
+
+func TestServer_organizations(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	category := s.AddCategory(&gocancel.Category{Name: gocancel.String("Telecom"), Slug: gocancel.String("telecom")})
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme"), CategoryID: category.ID})
+	s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Other")})
+	product := s.AddProduct(&gocancel.Product{Name: gocancel.String("Internet"), OrganizationID: org.ID})
+
+	orgs, _, err := client.Organizations.List(ctx, &gocancel.OrganizationsListOptions{Category: *category.ID})
+	if err != nil {
+		t.Fatalf("Organizations.List returned error: %v", err)
+	}
+	if len(orgs) != 1 || *orgs[0].ID != *org.ID {
+		t.Errorf("Organizations.List returned %+v, want only %s", orgs, *org.ID)
+	}
+
+	products, _, err := client.Organizations.ListProducts(ctx, *org.ID, nil)
+	if err != nil {
+		t.Fatalf("Organizations.ListProducts returned error: %v", err)
+	}
+	if len(products) != 1 || *products[0].ID != *product.ID {
+		t.Errorf("Organizations.ListProducts returned %+v, want only %s", products, *product.ID)
+	}
+
+	got, _, err := client.Organizations.GetProduct(ctx, *org.ID, *product.ID)
+	if err != nil {
+		t.Fatalf("Organizations.GetProduct returned error: %v", err)
+	}
+	if *got.Name != "Internet" {
+		t.Errorf("Organizations.GetProduct returned name %q, want %q", *got.Name, "Internet")
+	}
+
+	if _, _, err := client.Products.Get(ctx, *product.ID); err != nil {
+		t.Errorf("Products.Get returned error: %v", err)
+	}
+}
+
+func TestServer_pagination(t *testing.T) {
+	s, client := newTestServer(t)
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		p := s.AddProvider(&gocancel.Provider{ProviderType: gocancel.ProviderTypeEmail.Ptr()})
+		want = append(want, *p.ID)
+	}
+
+	it := client.Providers.ListAll(context.Background(), &gocancel.ProvidersListOptions{Limit: 2})
+
+	var got []string
+	for it.Next() {
+		got = append(got, *it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Providers.ListAll returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Providers.ListAll returned %v, want %v", got, want)
+	}
+}
+
+func TestServer_letters(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme")})
+
+	letter, resp, err := client.Letters.Create(ctx, &gocancel.LetterRequest{
+		OrganizationID: *org.ID,
+		Locale:         "nl-NL",
+	})
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Letters.Create responded with %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if *letter.OrganizationName != "Acme" || *letter.State != gocancel.LetterStateCreated {
+		t.Errorf("Letters.Create returned %+v", letter)
+	}
+
+	letter, _, err = client.Letters.Update(ctx, *letter.ID, &gocancel.LetterRequest{Locale: "en-US"})
+	if err != nil {
+		t.Fatalf("Letters.Update returned error: %v", err)
+	}
+	if *letter.Locale != "en-US" {
+		t.Errorf("Letters.Update returned locale %q, want %q", *letter.Locale, "en-US")
+	}
+
+	letter, _, err = client.Letters.MarkAsDrafted(ctx, *letter.ID, nil)
+	if err != nil {
+		t.Fatalf("Letters.MarkAsDrafted returned error: %v", err)
+	}
+	if *letter.State != gocancel.LetterStateDrafted {
+		t.Errorf("Letters.MarkAsDrafted returned state %q, want %q", *letter.State, gocancel.LetterStateDrafted)
+	}
+
+	letters, _, err := client.Letters.List(ctx, &gocancel.LettersListOptions{States: []gocancel.LetterState{gocancel.LetterStateDrafted}})
+	if err != nil {
+		t.Fatalf("Letters.List returned error: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Errorf("Letters.List returned %d letters, want 1", len(letters))
+	}
+
+	if _, err := client.Letters.Delete(ctx, *letter.ID); err != nil {
+		t.Fatalf("Letters.Delete returned error: %v", err)
+	}
+
+	_, _, err = client.Letters.Get(ctx, *letter.ID)
+	var nfErr *gocancel.NotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Errorf("Letters.Get returned %v, want a *NotFoundError", err)
+	}
+}
+
+func TestServer_listLettersFilters(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	may := &gocancel.Timestamp{Time: time.Date(2021, 5, 15, 0, 0, 0, 0, time.UTC)}
+	june := &gocancel.Timestamp{Time: time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)}
+	s.AddLetter(&gocancel.Letter{
+		ID:        gocancel.String("a"),
+		Metadata:  &gocancel.AccountMetadata{"order": "1"},
+		CreatedAt: may,
+		UpdatedAt: june,
+	})
+	s.AddLetter(&gocancel.Letter{
+		ID:        gocancel.String("b"),
+		Metadata:  &gocancel.AccountMetadata{"order": "2"},
+		CreatedAt: june,
+		UpdatedAt: june,
+	})
+
+	tests := []struct {
+		opts *gocancel.LettersListOptions
+		want []string
+	}{
+		{&gocancel.LettersListOptions{Metadata: gocancel.MetadataFilter{"order": "2"}}, []string{"b"}},
+		{&gocancel.LettersListOptions{Metadata: gocancel.MetadataFilter{"unknown": "1"}}, nil},
+		{&gocancel.LettersListOptions{CreatedAt: gocancel.LettersTimeRangeOptions{Before: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)}}, []string{"a"}},
+		{&gocancel.LettersListOptions{CreatedAt: gocancel.LettersTimeRangeOptions{After: june.Time}}, []string{"b"}},
+		{&gocancel.LettersListOptions{UpdatedAt: gocancel.LettersTimeRangeOptions{After: may.Time, Before: june.Time}}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		letters, _, err := client.Letters.List(ctx, tt.opts)
+		if err != nil {
+			t.Fatalf("Letters.List returned error: %v", err)
+		}
+
+		var got []string
+		for _, l := range letters {
+			got = append(got, *l.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Letters.List(%+v) returned %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", s.URL+"api/v1/letters?created_at[gte]=yesterday", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET with an invalid time range responded with %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestServer_SetLetterState(t *testing.T) {
+	s, client := newTestServer(t)
+	letter := s.AddLetter(&gocancel.Letter{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			if _, _, err := client.Letters.Get(context.Background(), *letter.ID); err != nil {
+				t.Errorf("Letters.Get returned error: %v", err)
+			}
+		}
+	}()
+	for _, state := range []gocancel.LetterState{gocancel.LetterStateGenerating, gocancel.LetterStateSent} {
+		if err := s.SetLetterState(*letter.ID, state); err != nil {
+			t.Fatalf("SetLetterState returned error: %v", err)
+		}
+	}
+	<-done
+
+	if got := *s.Letter(*letter.ID).State; got != gocancel.LetterStateSent {
+		t.Errorf("Letter has state %q, want %q", got, gocancel.LetterStateSent)
+	}
+	if *letter.State != gocancel.LetterStateCreated {
+		t.Errorf("SetLetterState modified the added letter in place")
+	}
+}
+
+func TestServer_createLetterValidation(t *testing.T) {
+	_, client := newTestServer(t)
+
+	_, _, err := client.Letters.Create(context.Background(), &gocancel.LetterRequest{OrganizationID: "unknown"})
+
+	var vErr *gocancel.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Letters.Create returned %v, want a *ValidationError", err)
+	}
+	if vErr.Field("organization_id") == nil {
+		t.Errorf("ValidationError has no error for organization_id: %v", vErr)
+	}
+}
+
+func TestServer_createLetterIdempotent(t *testing.T) {
+	s, client := newTestServer(t)
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme")})
+
+	ctx := gocancel.WithIdempotencyKey(context.Background(), "order-1")
+	req := &gocancel.LetterRequest{OrganizationID: *org.ID}
+
+	first, resp, err := client.Letters.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+	if resp.IdempotentReplayed {
+		t.Error("First Letters.Create response is replayed")
+	}
+
+	second, resp, err := client.Letters.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+	if !resp.IdempotentReplayed {
+		t.Error("Second Letters.Create response is not replayed")
+	}
+	if *second.ID != *first.ID {
+		t.Errorf("Letters.Create returned letter %s, want %s", *second.ID, *first.ID)
+	}
+
+	letters, _, err := client.Letters.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Letters.List returned error: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Errorf("Letters.List returned %d letters, want 1", len(letters))
+	}
+}
+
+func TestServer_downloads(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	letter := s.AddLetter(&gocancel.Letter{})
+	s.SetDocument(*letter.ID, []byte("%PDF-1.4"))
+	s.AddProofOfID(*letter.ID, "passport", []byte("image"))
+
+	tests := []struct {
+		name     string
+		download func() ([]byte, error)
+		want     string
+	}{
+		{"DownloadDocument", func() ([]byte, error) {
+			rc, _, err := client.Letters.DownloadDocument(ctx, *letter.ID)
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return ioutil.ReadAll(rc)
+		}, "%PDF-1.4"},
+		{"DownloadProofOfID", func() ([]byte, error) {
+			rc, _, err := client.Letters.DownloadProofOfID(ctx, *letter.ID, "passport")
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return ioutil.ReadAll(rc)
+		}, "image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.download()
+			if err != nil {
+				t.Fatalf("%s returned error: %v", tt.name, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s returned %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_InjectFailure(t *testing.T) {
+	s, client := newTestServer(t)
+
+	s.InjectFailure(Failure{
+		Method:  http.MethodGet,
+		Path:    "/api/v1/categories",
+		Status:  http.StatusServiceUnavailable,
+		Code:    "unavailable",
+		Message: "Try again later",
+		Times:   2,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, _, err := client.Categories.List(ctx, nil)
+
+		var sErr *gocancel.ServerError
+		if !errors.As(err, &sErr) {
+			t.Fatalf("Categories.List returned %v, want a *ServerError", err)
+		}
+		if sErr.Err.Code != "unavailable" {
+			t.Errorf("ServerError has code %q, want %q", sErr.Err.Code, "unavailable")
+		}
+	}
+
+	if _, _, err := client.Categories.List(ctx, nil); err != nil {
+		t.Errorf("Categories.List returned error after the injected failures: %v", err)
+	}
+}
+
+func TestServer_uploadProofOfID(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	content := []byte("%PDF-1.4 passport")
+	proofOfID, _, err := client.Letters.UploadProofOfID(ctx, bytes.NewReader(content), "passport.pdf", "")
+	if err != nil {
+		t.Fatalf("Letters.UploadProofOfID returned error: %v", err)
+	}
+
+	org := s.AddOrganization(&gocancel.Organization{Name: gocancel.String("Acme")})
+	letter, _, err := client.Letters.Create(ctx, &gocancel.LetterRequest{
+		OrganizationID: *org.ID,
+		ProofOfIDs:     []string{*proofOfID.ID},
+	})
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+
+	rc, _, err := client.Letters.DownloadProofOfID(ctx, *letter.ID, *proofOfID.ID)
+	if err != nil {
+		t.Fatalf("Letters.DownloadProofOfID returned error: %v", err)
+	}
+	defer rc.Close()
+
+	if got, _ := ioutil.ReadAll(rc); !bytes.Equal(got, content) {
+		t.Errorf("Letters.DownloadProofOfID returned %q, want %q", got, content)
+	}
+}
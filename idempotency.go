@@ -0,0 +1,95 @@
+package gocancel
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// headerIdempotentReplayed is set by the API on responses replayed for a
+// repeated Idempotency-Key.
+const headerIdempotentReplayed = "Idempotent-Replayed"
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx carrying an idempotency key. Any
+// POST, PUT, PATCH or DELETE request made with the returned context is sent
+// with the key in the Idempotency-Key header, so that the API performs it at
+// most once, however often it is sent. This allows, for example, a
+// LettersService.Create call that timed out to be repeated without sending
+// the letter twice:
+//
+//	ctx := gocancel.WithIdempotencyKey(ctx, orderID)
+//	letter, _, err := client.Letters.Create(ctx, req)
+//
+// Requests carrying an idempotency key are also retried by the RetryPolicy of
+// the client. Keys should be unique per operation, e.g. a UUID or an ID from
+// your own system; use a new context for each operation.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in ctx by
+// WithIdempotencyKey, if any.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// setIdempotencyKey returns req with an Idempotency-Key header from ctx. If
+// ctx has no key and the client retries requests, a random key is generated
+// for POST and PATCH requests made by service methods, making them safe to
+// retry. Requests built by the caller are left alone, as only the caller
+// knows whether they may be sent twice. req is returned as is if no header
+// is added.
+func (c *Client) setIdempotencyKey(ctx context.Context, req *http.Request) (*http.Request, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return req, nil
+	}
+
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok {
+		if isIdempotent(req) || c.retryPolicy == nil || c.retryPolicy.MaxAttempts <= 1 {
+			return req, nil
+		}
+		if OperationFromContext(ctx) == "" {
+			return req, nil
+		}
+		// A body that cannot be rebuilt cannot be retried, so do not bother.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return req, nil
+		}
+
+		var err error
+		if key, err = newIdempotencyKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	// req belongs to the caller, so set the header on a copy.
+	r := req.Clone(ctx)
+	r.Header.Set(headerIdempotencyKey, key)
+	return r, nil
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// idempotentReplayed reports whether r is a response replayed by the API for
+// a repeated idempotency key.
+func idempotentReplayed(r *http.Response) bool {
+	replayed, _ := strconv.ParseBool(r.Header.Get(headerIdempotentReplayed))
+	return replayed
+}
@@ -0,0 +1,135 @@
+package gocancel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testHeader(t, r, "Idempotency-Key", "order-1")
+		w.Header().Set("Idempotent-Replayed", "true")
+		fmt.Fprint(w, `{"letter":{"id":"b"}}`)
+	})
+
+	ctx := WithIdempotencyKey(context.Background(), "order-1")
+	if key, ok := IdempotencyKeyFromContext(ctx); !ok || key != "order-1" {
+		t.Errorf("IdempotencyKeyFromContext returned %q, %t, want %q, true", key, ok, "order-1")
+	}
+
+	_, resp, err := client.Letters.Create(ctx, &LetterRequest{OrganizationID: "a"})
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+	if resp.IdempotencyKey != "order-1" {
+		t.Errorf("Response.IdempotencyKey = %q, want %q", resp.IdempotencyKey, "order-1")
+	}
+	if !resp.IdempotentReplayed {
+		t.Error("Response.IdempotentReplayed = false, want true")
+	}
+}
+
+func TestWithIdempotencyKey_get(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Idempotency-Key", "")
+		fmt.Fprint(w, `{"letter":{"id":"b"}}`)
+	})
+
+	ctx := WithIdempotencyKey(context.Background(), "order-1")
+	_, resp, err := client.Letters.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Letters.Get returned error: %v", err)
+	}
+	if resp.IdempotencyKey != "" || resp.IdempotentReplayed {
+		t.Errorf("Response has idempotency key %q, replayed %t, want none", resp.IdempotencyKey, resp.IdempotentReplayed)
+	}
+}
+
+func TestBareDo_generatedIdempotencyKey(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	var keys []string
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"letter":{"id":"b"}}`)
+	})
+
+	ctx := context.Background()
+	input := &LetterRequest{OrganizationID: "a"}
+
+	// Without retries, no key is generated.
+	if _, _, err := client.Letters.Create(ctx, input); err == nil {
+		t.Fatal("Letters.Create returned no error")
+	}
+	if keys[0] != "" {
+		t.Errorf("Letters.Create sent idempotency key %q without retries, want none", keys[0])
+	}
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	keys = nil
+	_, resp, err := client.Letters.Create(ctx, input)
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+
+	uuidRE := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(keys) != 2 || !uuidRE.MatchString(keys[0]) || keys[0] != keys[1] {
+		t.Errorf("Letters.Create sent idempotency keys %q, want the same generated key twice", keys)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("Response.Attempts = %d, want 2", resp.Attempts)
+	}
+	if resp.IdempotencyKey != keys[len(keys)-1] {
+		t.Errorf("Response.IdempotencyKey = %q, want %q", resp.IdempotencyKey, keys[len(keys)-1])
+	}
+
+	// Requests built by the caller are not given a key, so they are not
+	// retried either.
+	req, _ := client.NewRequest("POST", "api/v1/letters", input)
+	keys = nil
+	if _, err := client.Do(ctx, req, nil); err == nil {
+		t.Fatal("Do returned no error")
+	}
+	if len(keys) != 1 || keys[0] != "" {
+		t.Errorf("Do sent idempotency keys %q, want a single request without key", keys)
+	}
+}
+
+func TestBareDo_idempotencyKeyCopiesRequest(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Idempotency-Key", "order-1")
+		fmt.Fprint(w, `{}`)
+	})
+
+	req, _ := client.NewRequest("POST", "api/v1/letters", &LetterRequest{OrganizationID: "a"})
+	header := req.Header
+
+	ctx := WithIdempotencyKey(context.Background(), "order-1")
+	if _, err := client.Do(ctx, req, nil); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if got := header.Get("Idempotency-Key"); got != "" {
+		t.Errorf("Do modified the header of the request, Idempotency-Key = %q", got)
+	}
+}
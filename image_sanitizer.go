@@ -0,0 +1,334 @@
+package gocancel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+)
+
+// maxImagePixels limits the resolution of images decoded by an
+// ImageSanitizer, guarding against decompression bombs.
+const maxImagePixels = 50_000_000
+
+// ErrImageTooLarge is returned when sanitizing an image with more pixels than
+// an ImageSanitizer is willing to decode.
+var ErrImageTooLarge = errors.New("image resolution is too large")
+
+// ImageSanitizer preprocesses JPEG and PNG images before they are uploaded as
+// proof of ID. Images are decoded and encoded again using the standard
+// library codecs, which drops all metadata such as EXIF and XMP, including GPS
+// coordinates and device information. The EXIF orientation is applied to the
+// pixels first, so the image is still displayed upright without it.
+type ImageSanitizer struct {
+	// MaxDimension is the maximum width and height of the sanitized image in
+	// pixels. Larger images are downsized, keeping their aspect ratio. Zero
+	// means no limit.
+	MaxDimension int
+
+	// JPEGQuality is the quality of re-encoded JPEG images, ranging from 1
+	// to 100. Defaults to jpeg.DefaultQuality when zero.
+	JPEGQuality int
+}
+
+// DefaultImageSanitizer is a sensible ImageSanitizer for photos of identity
+// documents.
+var DefaultImageSanitizer = ImageSanitizer{
+	MaxDimension: 2560,
+	JPEGQuality:  90,
+}
+
+// SetImageSanitizer is a client option for sanitizing JPEG and PNG images
+// before uploading them using LettersService.UploadProofOfID.
+func SetImageSanitizer(s ImageSanitizer) ClientOpt {
+	return func(c *Client) error {
+		if s.MaxDimension < 0 {
+			return errors.New("image sanitizer max dimension must not be negative")
+		}
+		if s.JPEGQuality < 0 || s.JPEGQuality > 100 {
+			return fmt.Errorf("image sanitizer JPEG quality %d is out of range", s.JPEGQuality)
+		}
+
+		c.imageSanitizer = &s
+		return nil
+	}
+}
+
+// Sanitize reads an image of the given content type, either "image/jpeg" or
+// "image/png", from r and returns it re-encoded in the same format, without
+// metadata, upright and downsized to MaxDimension.
+func (s ImageSanitizer) Sanitize(r io.Reader, contentType string) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxProofOfIDSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxProofOfIDSize {
+		return nil, ErrProofOfIDTooLarge
+	}
+
+	var orientation int
+	switch contentType {
+	case "image/jpeg":
+		orientation = jpegOrientation(data)
+	case "image/png":
+		orientation = pngOrientation(data)
+	default:
+		return nil, fmt.Errorf("cannot sanitize images of type %q", contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	img = orient(img, orientation)
+	img = downsize(img, s.MaxDimension)
+
+	buf := new(bytes.Buffer)
+	if contentType == "image/jpeg" {
+		quality := s.JPEGQuality
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// orient returns img transformed according to an EXIF orientation, so that it
+// is upright when displayed without the orientation.
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		// Orientations 5 to 8 swap the axes.
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+
+			si := img.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// downsize returns img scaled down to fit within max by max pixels, averaging
+// the source pixels covered by each destination pixel. Images that already
+// fit are returned as is.
+func downsize(img *image.NRGBA, max int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if max <= 0 || (w <= max && h <= max) {
+		return img
+	}
+
+	dw, dh := max, h*max/w
+	if h > w {
+		dw, dh = w*max/h, max
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := span(y, h, dh)
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := span(x, w, dw)
+
+			// Weigh the colors by their alpha, so transparent pixels do
+			// not bleed into their neighbours.
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				i := img.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					pa := uint64(img.Pix[i+3])
+					r += uint64(img.Pix[i]) * pa
+					g += uint64(img.Pix[i+1]) * pa
+					b += uint64(img.Pix[i+2]) * pa
+					a += pa
+					n++
+					i += 4
+				}
+			}
+
+			di := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[di] = uint8(r / a)
+				dst.Pix[di+1] = uint8(g / a)
+				dst.Pix[di+2] = uint8(b / a)
+			}
+			dst.Pix[di+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// span returns the range of source pixels covered by destination pixel i,
+// when scaling size source pixels to dsize destination pixels.
+func span(i, size, dsize int) (int, int) {
+	start := i * size / dsize
+	end := (i + 1) * size / dsize
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, or 0 if it
+// has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return 0
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 0
+		}
+
+		marker := data[i+1]
+		if marker == 0xda { // start of scan, no metadata follows
+			return 0
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 0
+		}
+
+		segment := data[i+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		i = end
+	}
+
+	return 0
+}
+
+// pngOrientation returns the EXIF orientation of a PNG image, or 0 if it has
+// none.
+func pngOrientation(data []byte) int {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return 0
+	}
+
+	// Every chunk consists of its length, type, data and CRC.
+	for i := len(signature); i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		typ := string(data[i+4 : i+8])
+		end := i + 8 + length + 4
+		if length < 0 || end > len(data) {
+			return 0
+		}
+
+		switch typ {
+		case "eXIf":
+			return tiffOrientation(data[i+8 : i+8+length])
+		case "IEND":
+			return 0
+		}
+
+		i = end
+	}
+
+	return 0
+}
+
+// tiffOrientation returns the value of the orientation tag in the first IFD
+// of EXIF data, which is structured like a TIFF file.
+func tiffOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return 0
+	}
+
+	ifd := int(order.Uint32(data[4:]))
+	if ifd < 8 || ifd+2 > len(data) {
+		return 0
+	}
+
+	entries := int(order.Uint16(data[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(data) {
+			return 0
+		}
+
+		const tagOrientation, typeShort = 0x0112, 3
+		if order.Uint16(data[entry:]) == tagOrientation && order.Uint16(data[entry+2:]) == typeShort {
+			return int(order.Uint16(data[entry+8:]))
+		}
+	}
+
+	return 0
+}
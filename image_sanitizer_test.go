@@ -0,0 +1,255 @@
+package gocancel
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+var (
+	red  = color.NRGBA{R: 255, A: 255}
+	blue = color.NRGBA{B: 255, A: 255}
+)
+
+// testImage returns a blue image of the given size with a red top-left pixel.
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, blue)
+		}
+	}
+	img.SetNRGBA(0, 0, red)
+	return img
+}
+
+// exifData returns EXIF data holding only the given orientation.
+func exifData(order binary.ByteOrder, orientation int) []byte {
+	b := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(b, "II")
+	} else {
+		copy(b, "MM")
+	}
+	order.PutUint16(b[2:], 42)
+	order.PutUint32(b[4:], 8) // offset of the first IFD
+	order.PutUint16(b[8:], 1) // number of entries
+	order.PutUint16(b[10:], 0x0112)
+	order.PutUint16(b[12:], 3) // SHORT
+	order.PutUint32(b[14:], 1)
+	order.PutUint16(b[18:], uint16(orientation))
+	return b // the offset of the next IFD is zero
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	b := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	copy(b[4:], typ)
+	b = append(b, data...)
+	return append(b, make([]byte, 4)...)
+}
+
+// withPNGExif returns a PNG image with an eXIf chunk after its header.
+func withPNGExif(t *testing.T, img image.Image, orientation int) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := pngChunk("eXIf", exifData(binary.BigEndian, orientation))
+	binary.BigEndian.PutUint32(chunk[len(chunk)-4:], crc32.ChecksumIEEE(chunk[4:len(chunk)-4]))
+
+	// The signature and IHDR chunk take 33 bytes.
+	data := buf.Bytes()
+	return append(append(append([]byte(nil), data[:33]...), chunk...), data[33:]...)
+}
+
+// withJPEGExif returns a JPEG image with an APP1 EXIF segment.
+func withJPEGExif(t *testing.T, img image.Image, orientation int) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := append([]byte("Exif\x00\x00"), exifData(binary.LittleEndian, orientation)...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte(nil), data[:2]...), segment...), data[2:]...)
+}
+
+func TestImageSanitizer_Sanitize_orientation(t *testing.T) {
+	tests := []struct {
+		orientation int
+		size        image.Point
+		red         image.Point
+	}{
+		{1, image.Pt(3, 2), image.Pt(0, 0)},
+		{2, image.Pt(3, 2), image.Pt(2, 0)},
+		{3, image.Pt(3, 2), image.Pt(2, 1)},
+		{4, image.Pt(3, 2), image.Pt(0, 1)},
+		{5, image.Pt(2, 3), image.Pt(0, 0)},
+		{6, image.Pt(2, 3), image.Pt(1, 0)},
+		{7, image.Pt(2, 3), image.Pt(1, 2)},
+		{8, image.Pt(2, 3), image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		data := withPNGExif(t, testImage(3, 2), tt.orientation)
+		if got := pngOrientation(data); got != tt.orientation {
+			t.Errorf("pngOrientation returned %d, want %d", got, tt.orientation)
+		}
+
+		out, err := ImageSanitizer{}.Sanitize(bytes.NewReader(data), "image/png")
+		if err != nil {
+			t.Fatalf("Sanitize returned error: %v", err)
+		}
+		if bytes.Contains(out, []byte("eXIf")) {
+			t.Errorf("Orientation %d: sanitized image contains EXIF data", tt.orientation)
+		}
+
+		img, err := png.Decode(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("Decoding sanitized image returned error: %v", err)
+		}
+
+		if got := img.Bounds().Size(); got != tt.size {
+			t.Errorf("Orientation %d: sanitized image has size %v, want %v", tt.orientation, got, tt.size)
+		}
+		if got := color.NRGBAModel.Convert(img.At(tt.red.X, tt.red.Y)); got != red {
+			t.Errorf("Orientation %d: pixel %v = %v, want red", tt.orientation, tt.red, got)
+		}
+	}
+}
+
+func TestImageSanitizer_Sanitize_jpeg(t *testing.T) {
+	data := withJPEGExif(t, testImage(32, 16), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Errorf("jpegOrientation returned %d, want 6", got)
+	}
+
+	out, err := DefaultImageSanitizer.Sanitize(bytes.NewReader(data), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Sanitize returned error: %v", err)
+	}
+	if bytes.Contains(out, []byte("Exif")) {
+		t.Error("Sanitized image contains EXIF data")
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("Decoding sanitized image returned error: %v", err)
+	}
+	if cfg.Width != 16 || cfg.Height != 32 {
+		t.Errorf("Sanitized image has size %dx%d, want 16x32", cfg.Width, cfg.Height)
+	}
+}
+
+func TestImageSanitizer_Sanitize_downsize(t *testing.T) {
+	img := testImage(400, 100)
+	img.SetNRGBA(0, 0, blue)
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ImageSanitizer{MaxDimension: 100}.Sanitize(buf, "image/png")
+	if err != nil {
+		t.Fatalf("Sanitize returned error: %v", err)
+	}
+
+	got, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("Decoding sanitized image returned error: %v", err)
+	}
+	if size := got.Bounds().Size(); size != image.Pt(100, 25) {
+		t.Errorf("Sanitized image has size %v, want %v", size, image.Pt(100, 25))
+	}
+	if c := color.NRGBAModel.Convert(got.At(50, 10)); c != blue {
+		t.Errorf("Sanitized image has color %v, want %v", c, blue)
+	}
+}
+
+func TestImageSanitizer_Sanitize_tooManyPixels(t *testing.T) {
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header, 10000)
+	binary.BigEndian.PutUint32(header[4:], 10000)
+	header[8], header[9] = 8, 6 // 8-bit RGBA
+
+	ihdr := pngChunk("IHDR", header)
+	binary.BigEndian.PutUint32(ihdr[len(ihdr)-4:], crc32.ChecksumIEEE(ihdr[4:len(ihdr)-4]))
+	data := append([]byte("\x89PNG\r\n\x1a\n"), ihdr...)
+
+	_, err := ImageSanitizer{}.Sanitize(bytes.NewReader(data), "image/png")
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Sanitize returned %v, want %v", err, ErrImageTooLarge)
+	}
+}
+
+func TestTiffOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		if got := tiffOrientation(exifData(order, 8)); got != 8 {
+			t.Errorf("tiffOrientation(%v) returned %d, want 8", order, got)
+		}
+	}
+
+	for _, data := range [][]byte{nil, []byte("XX\x00\x2a\x00\x00\x00\x08"), exifData(binary.BigEndian, 3)[:12]} {
+		if got := tiffOrientation(data); got != 0 {
+			t.Errorf("tiffOrientation(%q) returned %d, want 0", data, got)
+		}
+	}
+}
+
+func TestSetImageSanitizer(t *testing.T) {
+	if _, err := New(nil, SetImageSanitizer(ImageSanitizer{JPEGQuality: 101})); err == nil {
+		t.Error("SetImageSanitizer accepted an invalid JPEG quality")
+	}
+	if _, err := New(nil, SetImageSanitizer(ImageSanitizer{MaxDimension: -1})); err == nil {
+		t.Error("SetImageSanitizer accepted a negative max dimension")
+	}
+}
+
+func TestLettersService_UploadProofOfID_sanitized(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	client.imageSanitizer = &ImageSanitizer{}
+
+	mux.HandleFunc("/api/v1/proof_of_ids", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Reading uploaded file returned error: %v", err)
+		}
+		defer file.Close()
+
+		cfg, err := png.DecodeConfig(file)
+		if err != nil {
+			t.Fatalf("Decoding uploaded image returned error: %v", err)
+		}
+		if cfg.Width != 2 || cfg.Height != 3 {
+			t.Errorf("Uploaded image has size %dx%d, want 2x3", cfg.Width, cfg.Height)
+		}
+
+		w.Write([]byte(`{"proof_of_id":{"id":"p"}}`))
+	})
+
+	data := withPNGExif(t, testImage(3, 2), 6)
+	if _, _, err := client.Letters.UploadProofOfID(context.Background(), bytes.NewReader(data), "passport.png", ""); err != nil {
+		t.Fatalf("Letters.UploadProofOfID returned error: %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The names of the metrics recorded through a Meter.
+const (
+	// MetricRequests counts API calls.
+	MetricRequests = "gocancel.client.requests"
+
+	// MetricRetries counts the attempts made in addition to the initial one.
+	MetricRetries = "gocancel.client.retries"
+
+	// MetricDuration records the duration of API calls in seconds, including
+	// retries but excluding the transfer of downloaded documents.
+	MetricDuration = "gocancel.client.duration"
+
+	// MetricRateLimitRemaining records the remaining rate limit reported by
+	// each response.
+	MetricRateLimitRemaining = "gocancel.client.rate_limit.remaining"
+
+	// MetricDownloadedBytes counts the bytes of downloaded documents.
+	MetricDownloadedBytes = "gocancel.client.downloaded_bytes"
+)
+
+// The keys of the attributes set on spans and metrics.
+const (
+	AttributeOperation          = "gocancel.operation"
+	AttributeMethod             = "http.method"
+	AttributePath               = "http.path"
+	AttributeStatusCode         = "http.status_code"
+	AttributeAttempts           = "gocancel.attempts"
+	AttributeRateLimitRemaining = "gocancel.rate_limit.remaining"
+	AttributeDownloadedBytes    = "gocancel.downloaded_bytes"
+)
+
+// Attribute is a key-value pair describing a span or a measurement.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans around API calls, see SetTracer. It is typically an
+// adapter to a tracing library such as OpenTelemetry.
+type Tracer interface {
+	// Start starts a span and returns a context carrying it, which is used
+	// for the request and passed to the Propagator.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+
+	// End ends the span, recording err if it is non-nil.
+	End(err error)
+}
+
+// Meter records metrics about API calls, see SetMeter.
+type Meter interface {
+	// Add adds value to a counter.
+	Add(ctx context.Context, name string, value int64, attrs ...Attribute)
+
+	// Record records value in a histogram.
+	Record(ctx context.Context, name string, value float64, attrs ...Attribute)
+}
+
+// Propagator injects the trace context of ctx into the headers of outgoing
+// requests, e.g. as a W3C traceparent header, see SetPropagator.
+type Propagator interface {
+	Inject(ctx context.Context, header http.Header)
+}
+
+// SetTracer is a client option for tracing every API call. Each call is
+// wrapped in a span named after the service method, e.g. "Letters.Create".
+// The span covers all retry attempts and, for downloaded documents, lasts
+// until the body is closed.
+func SetTracer(t Tracer) ClientOpt {
+	return func(c *Client) error {
+		if t == nil {
+			return errors.New("tracer must be non-nil")
+		}
+		c.tracer = t
+		return nil
+	}
+}
+
+// SetMeter is a client option for recording metrics about every API call.
+// See MetricRequests and the other metric names for the recorded metrics.
+func SetMeter(m Meter) ClientOpt {
+	return func(c *Client) error {
+		if m == nil {
+			return errors.New("meter must be non-nil")
+		}
+		c.meter = m
+		return nil
+	}
+}
+
+// SetPropagator is a client option for propagating the trace context to the
+// API with every request.
+func SetPropagator(p Propagator) ClientOpt {
+	return func(c *Client) error {
+		if p == nil {
+			return errors.New("propagator must be non-nil")
+		}
+		c.propagator = p
+		return nil
+	}
+}
+
+// instrument sends req using h, tracing it and recording metrics when the
+// client is configured to.
+func (c *Client) instrument(req *http.Request, h Handler) (*Response, error) {
+	if c.tracer == nil && c.meter == nil && c.propagator == nil {
+		return h(req)
+	}
+
+	ctx := req.Context()
+	op := OperationFromContext(ctx)
+	name := op
+	if name == "" {
+		name = req.Method + " " + req.URL.Path
+	}
+	attrs := []Attribute{
+		{AttributeOperation, op},
+		{AttributeMethod, req.Method},
+		{AttributePath, req.URL.Path},
+	}
+
+	var span Span
+	if c.tracer != nil {
+		ctx, span = c.tracer.Start(ctx, name, attrs...)
+		req = req.WithContext(ctx)
+	}
+	if c.propagator != nil {
+		// The header of req is shared with the caller, so copy it first.
+		req.Header = req.Header.Clone()
+		c.propagator.Inject(ctx, req.Header)
+	}
+
+	start := time.Now()
+	resp, err := h(req)
+	duration := time.Since(start)
+
+	var spanAttrs []Attribute
+	if resp != nil {
+		attrs = append(attrs, Attribute{AttributeStatusCode, resp.StatusCode})
+		spanAttrs = append(spanAttrs,
+			Attribute{AttributeStatusCode, resp.StatusCode},
+			Attribute{AttributeAttempts, resp.Attempts},
+		)
+		if resp.Rate.Limit > 0 {
+			spanAttrs = append(spanAttrs, Attribute{AttributeRateLimitRemaining, resp.Rate.Remaining})
+		}
+	}
+	if span != nil && len(spanAttrs) > 0 {
+		span.SetAttributes(spanAttrs...)
+	}
+
+	if c.meter != nil {
+		c.meter.Add(ctx, MetricRequests, 1, attrs...)
+		c.meter.Record(ctx, MetricDuration, duration.Seconds(), attrs...)
+		if resp != nil && resp.Attempts > 1 {
+			c.meter.Add(ctx, MetricRetries, int64(resp.Attempts-1), attrs...)
+		}
+		if resp != nil && resp.Rate.Limit > 0 {
+			c.meter.Record(ctx, MetricRateLimitRemaining, float64(resp.Rate.Remaining), attrs...)
+		}
+	}
+
+	// Downloaded documents are read after BareDo returns, so the span ends
+	// when the body is closed. JSON bodies are read by Do.
+	if err == nil && resp != nil && resp.Body != nil && !isTextual(resp.Header) {
+		resp.Body = &instrumentedBody{
+			ReadCloser: resp.Body,
+			done: func(n int64, readErr error) {
+				if c.meter != nil {
+					c.meter.Add(ctx, MetricDownloadedBytes, n, attrs...)
+				}
+				if span != nil {
+					span.SetAttributes(Attribute{AttributeDownloadedBytes, n})
+					span.End(readErr)
+				}
+			},
+		}
+		return resp, err
+	}
+
+	if span != nil {
+		span.End(err)
+	}
+	return resp, err
+}
+
+// instrumentedBody counts the bytes read from a response body, and calls done
+// once when the body is closed.
+type instrumentedBody struct {
+	io.ReadCloser
+	n    int64
+	err  error
+	once sync.Once
+	done func(n int64, err error)
+}
+
+func (b *instrumentedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *instrumentedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n, b.err) })
+	return err
+}
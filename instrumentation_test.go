@@ -0,0 +1,212 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	ended bool
+	err   error
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) End(err error) {
+	s.ended, s.err = true, err
+}
+
+type spanContextKey struct{}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &testSpan{name: name, attrs: make(map[string]interface{})}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanContextKey{}, s), s
+}
+
+type testMeter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	values map[string][]float64
+}
+
+func newTestMeter() *testMeter {
+	return &testMeter{counts: make(map[string]int64), values: make(map[string][]float64)}
+}
+
+func (m *testMeter) Add(ctx context.Context, name string, value int64, attrs ...Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[name] += value
+}
+
+func (m *testMeter) Record(ctx context.Context, name string, value float64, attrs ...Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] = append(m.values[name], value)
+}
+
+type testPropagator struct{}
+
+func (testPropagator) Inject(ctx context.Context, header http.Header) {
+	if s, ok := ctx.Value(spanContextKey{}).(*testSpan); ok {
+		header.Set("Traceparent", s.name)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	tracer, meter := new(testTracer), newTestMeter()
+	for _, opt := range []ClientOpt{SetTracer(tracer), SetMeter(meter), SetPropagator(testPropagator{}), SetRetryPolicy(testRetryPolicy)} {
+		if err := opt(client); err != nil {
+			t.Fatalf("Client option returned error: %v", err)
+		}
+	}
+
+	calls := 0
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Traceparent", "Letters.Get")
+		calls++
+		w.Header().Set(headerRateLimit, "60")
+		w.Header().Set(headerRateRemaining, "42")
+		w.Header().Set(headerRateReset, "1622116145")
+		if calls == 1 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"letter":{"id":"b"}}`)
+	})
+
+	if _, _, err := client.Letters.Get(context.Background(), "b"); err != nil {
+		t.Fatalf("Letters.Get returned error: %v", err)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Tracer started %d spans, want 1", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	if s.name != "Letters.Get" || !s.ended || s.err != nil {
+		t.Errorf("Span %q ended %t with error %v", s.name, s.ended, s.err)
+	}
+	want := map[string]interface{}{
+		AttributeOperation:          "Letters.Get",
+		AttributeMethod:             "GET",
+		AttributePath:               "/api/v1/letters/b",
+		AttributeStatusCode:         http.StatusOK,
+		AttributeAttempts:           2,
+		AttributeRateLimitRemaining: 42,
+	}
+	for k, v := range want {
+		if s.attrs[k] != v {
+			t.Errorf("Span attribute %s = %v, want %v", k, s.attrs[k], v)
+		}
+	}
+
+	if meter.counts[MetricRequests] != 1 || meter.counts[MetricRetries] != 1 {
+		t.Errorf("Meter counts = %v, want 1 request and 1 retry", meter.counts)
+	}
+	if v := meter.values[MetricRateLimitRemaining]; len(v) != 1 || v[0] != 42 {
+		t.Errorf("Meter recorded rate limit remaining %v, want [42]", v)
+	}
+	if len(meter.values[MetricDuration]) != 1 {
+		t.Errorf("Meter recorded durations %v, want one", meter.values[MetricDuration])
+	}
+}
+
+func TestInstrumentation_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	tracer := new(testTracer)
+	if err := SetTracer(tracer)(client); err != nil {
+		t.Fatalf("SetTracer returned error: %v", err)
+	}
+
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"not_found","message":"Letter not found"}}`)
+	})
+
+	_, _, err := client.Letters.Get(context.Background(), "b")
+
+	s := tracer.spans[0]
+	var nfErr *NotFoundError
+	if !s.ended || !errors.As(s.err, &nfErr) || err != s.err {
+		t.Errorf("Span ended %t with error %v, want %v", s.ended, s.err, err)
+	}
+	if s.attrs[AttributeStatusCode] != http.StatusNotFound {
+		t.Errorf("Span status code = %v, want %d", s.attrs[AttributeStatusCode], http.StatusNotFound)
+	}
+}
+
+func TestInstrumentation_download(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	tracer, meter := new(testTracer), newTestMeter()
+	if err := SetTracer(tracer)(client); err != nil {
+		t.Fatalf("SetTracer returned error: %v", err)
+	}
+	if err := SetMeter(meter)(client); err != nil {
+		t.Fatalf("SetMeter returned error: %v", err)
+	}
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(testDocument)
+	})
+
+	body, _, err := client.Letters.DownloadDocument(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("Letters.DownloadDocument returned error: %v", err)
+	}
+
+	s := tracer.spans[0]
+	if s.ended {
+		t.Error("Span ended before the document was read")
+	}
+
+	if _, err := ioutil.ReadAll(body); err != nil {
+		t.Fatalf("Reading document returned error: %v", err)
+	}
+	body.Close()
+	body.Close()
+
+	n := int64(len(testDocument))
+	if !s.ended || s.attrs[AttributeDownloadedBytes] != n {
+		t.Errorf("Span ended %t with %v downloaded bytes, want %d", s.ended, s.attrs[AttributeDownloadedBytes], n)
+	}
+	if meter.counts[MetricDownloadedBytes] != n {
+		t.Errorf("Meter counted %d downloaded bytes, want %d", meter.counts[MetricDownloadedBytes], n)
+	}
+}
+
+func TestSetTracer_nil(t *testing.T) {
+	if _, err := New(nil, SetTracer(nil)); err == nil {
+		t.Error("SetTracer accepted a nil tracer")
+	}
+	if _, err := New(nil, SetMeter(nil)); err == nil {
+		t.Error("SetMeter accepted a nil meter")
+	}
+	if _, err := New(nil, SetPropagator(nil)); err == nil {
+		t.Error("SetPropagator accepted a nil propagator")
+	}
+}
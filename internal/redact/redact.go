@@ -0,0 +1,108 @@
+// Package redact masks the values of fields holding personal data in JSON
+// documents. It is shared by the request logger of the gocancel package and
+// the scrubbers of the recorder package, so both mask the same fields the
+// same way.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Fields is a set of JSON object field names. Names are matched
+// case-insensitively.
+type Fields map[string]bool
+
+// NewFields returns a set of the given field names.
+func NewFields(names ...string) Fields {
+	f := make(Fields, len(names))
+	for _, name := range names {
+		f[strings.ToLower(name)] = true
+	}
+	return f
+}
+
+// Has reports whether the set contains name.
+func (f Fields) Has(name string) bool {
+	return f[strings.ToLower(name)]
+}
+
+// Walker masks values within a decoded JSON document.
+type Walker struct {
+	// Fields are the object fields whose values are masked, at any depth.
+	Fields Fields
+
+	// Mask returns the replacement of the value of a field in Fields. It is
+	// not called for null values, which are left as is.
+	Mask func(v interface{}) interface{}
+
+	// String optionally returns the replacement of a string value outside
+	// the masked fields.
+	String func(s string) string
+}
+
+// Walk masks the values within v, which is modified in place, and returns the
+// result. It reports whether any value was replaced.
+func (w *Walker) Walk(v interface{}) (interface{}, bool) {
+	changed := false
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if w.Fields.Has(k) {
+				if fv != nil {
+					v[k] = w.Mask(fv)
+					changed = true
+				}
+				continue
+			}
+
+			var c bool
+			if v[k], c = w.Walk(fv); c {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, ev := range v {
+			var c bool
+			if v[i], c = w.Walk(ev); c {
+				changed = true
+			}
+		}
+	case string:
+		if w.String != nil {
+			if s := w.String(v); s != v {
+				return s, true
+			}
+		}
+	}
+
+	return v, changed
+}
+
+// JSON masks the values within the JSON document body and returns the
+// re-encoded document. It reports whether body is a JSON document and whether
+// any value was replaced; body is returned as is if not.
+func (w *Walker) JSON(body []byte) (masked []byte, ok, changed bool) {
+	// Decode numbers as json.Number to write them back unchanged.
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	var v interface{}
+	if d.Decode(&v) != nil {
+		return body, false, false
+	}
+
+	v, changed = w.Walk(v)
+	if !changed {
+		return body, true, false
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body, false, false
+	}
+
+	return data, true, true
+}
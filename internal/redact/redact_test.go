@@ -0,0 +1,31 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalker_JSON(t *testing.T) {
+	w := &Walker{
+		Fields: NewFields("Email", "address"),
+		Mask:   func(v interface{}) interface{} { return "x" },
+		String: strings.ToUpper,
+	}
+
+	tests := []struct {
+		body, want  string
+		ok, changed bool
+	}{
+		{`{"email":"a@b.c","ADDRESS":{"line":"a"},"note":"hi","n":12345678901234567890}`, `{"ADDRESS":"x","email":"x","n":12345678901234567890,"note":"HI"}`, true, true},
+		{`{"email":null,"items":[{"address":"a"}]}`, `{"email":null,"items":[{"address":"x"}]}`, true, true},
+		{`{ "id": "1" }`, `{ "id": "1" }`, true, false},
+		{`not json`, `not json`, false, false},
+	}
+
+	for _, tt := range tests {
+		got, ok, changed := w.JSON([]byte(tt.body))
+		if string(got) != tt.want || ok != tt.ok || changed != tt.changed {
+			t.Errorf("JSON(%s) = %s, %t, %t, want %s, %t, %t", tt.body, got, ok, changed, tt.want, tt.ok, tt.changed)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package gocancel
+
+import (
+	"context"
+)
+
+// pageFetcher fetches the page of items at cursor and returns the number of
+// items on that page. An empty cursor denotes the first page.
+type pageFetcher func(ctx context.Context, cursor string) (int, *Response, error)
+
+// iterator implements the cursor pagination shared by all typed iterators,
+// such as OrganizationsIterator. It follows the cursors returned in the
+// response metadata until there are no more pages.
+type iterator struct {
+	ctx   context.Context
+	fetch pageFetcher
+
+	cursor   string
+	backward bool
+	maxItems int
+
+	index int // position of the current item on the current page
+	size  int // number of items on the current page
+	count int // number of items returned so far
+	done  bool
+
+	resp *Response
+	err  error
+}
+
+func newIterator(ctx context.Context, cursor string, fetch pageFetcher) iterator {
+	it := iterator{ctx: ctx, cursor: cursor, fetch: fetch}
+	if ctx == nil {
+		it.err = errNonNilContext
+	}
+
+	return it
+}
+
+// Next advances the iterator to the next item, fetching the next page when
+// the current one is exhausted. It returns false when there are no more
+// items, the maximum number of items has been reached or an error occurred.
+// Err should be consulted after Next returns false.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.maxItems > 0 && it.count >= it.maxItems {
+		return false
+	}
+
+	it.index++
+	for it.index >= it.size {
+		if it.done {
+			return false
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		size, resp, err := it.fetch(it.ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.resp = resp
+		it.index = 0
+		it.size = size
+
+		it.cursor = ""
+		if resp != nil && resp.Metadata != nil {
+			if it.backward {
+				it.cursor = resp.Metadata.PreviousCursor
+			} else {
+				it.cursor = resp.Metadata.NextCursor
+			}
+		}
+		if it.cursor == "" {
+			it.done = true
+		}
+	}
+
+	it.count++
+	return true
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Response returns the response of the most recently fetched page.
+func (it *iterator) Response() *Response {
+	return it.resp
+}
+
+// SetMaxItems caps the total number of items returned by the iterator. A
+// value of zero or less means no cap. It must be called before the first
+// call to Next.
+func (it *iterator) SetMaxItems(n int) {
+	it.maxItems = n
+}
+
+// SetBackward makes the iterator follow the previous cursors instead of the
+// next cursors, paging backwards from the cursor in the list options. It must
+// be called before the first call to Next.
+func (it *iterator) SetBackward(backward bool) {
+	it.backward = backward
+}
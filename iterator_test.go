@@ -0,0 +1,187 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// setupPages registers a handler on mux at path that serves the pages of a
+// cursor paginated list of organizations. Each page is addressed by its index
+// as cursor, the first page is served when no cursor is given.
+func setupPages(t *testing.T, mux *http.ServeMux, path string, pages [][]string) *int {
+	t.Helper()
+
+	var calls int
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		calls++
+
+		var page int
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			fmt.Sscanf(c, "%d", &page)
+		}
+
+		var next, prev string
+		if page+1 < len(pages) {
+			next = fmt.Sprint(page + 1)
+		}
+		if page > 0 {
+			prev = fmt.Sprint(page - 1)
+		}
+
+		var items string
+		for i, id := range pages[page] {
+			if i > 0 {
+				items += ","
+			}
+			items += fmt.Sprintf(`{"id":%q}`, id)
+		}
+
+		fmt.Fprintf(w, `{"organizations": [%s], "metadata": {"next_cursor": %q, "previous_cursor": %q}}`, items, next, prev)
+	})
+
+	return &calls
+}
+
+func organizationIDs(orgs []*Organization) []string {
+	var ids []string
+	for _, o := range orgs {
+		ids = append(ids, *o.ID)
+	}
+	return ids
+}
+
+func TestIterator_pages(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := setupPages(t, mux, "/api/v1/organizations", [][]string{{"a", "b"}, {}, {"c"}})
+
+	it := client.Organizations.ListAll(context.Background(), &OrganizationsListOptions{Limit: 2})
+
+	var got []string
+	for it.Next() {
+		got = append(got, *it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator returned error: %v", err)
+	}
+
+	if want := []string{"a", "b", "c"}; !cmp.Equal(got, want) {
+		t.Errorf("Iterator returned %v, want %v", got, want)
+	}
+	if *calls != 3 {
+		t.Errorf("Iterator fetched %d pages, want 3", *calls)
+	}
+	if it.Next() {
+		t.Errorf("Iterator.Next returned true after exhaustion")
+	}
+	if it.Response() == nil {
+		t.Errorf("Iterator.Response returned nil")
+	}
+}
+
+func TestIterator_maxItems(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := setupPages(t, mux, "/api/v1/organizations", [][]string{{"a", "b"}, {"c", "d"}, {"e"}})
+
+	it := client.Organizations.ListAll(context.Background(), nil)
+	it.SetMaxItems(3)
+
+	orgs, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Iterator.Collect returned error: %v", err)
+	}
+
+	if got, want := organizationIDs(orgs), []string{"a", "b", "c"}; !cmp.Equal(got, want) {
+		t.Errorf("Iterator.Collect returned %v, want %v", got, want)
+	}
+	if *calls != 2 {
+		t.Errorf("Iterator fetched %d pages, want 2", *calls)
+	}
+}
+
+func TestIterator_backward(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	setupPages(t, mux, "/api/v1/organizations", [][]string{{"a"}, {"b"}, {"c"}})
+
+	it := client.Organizations.ListAll(context.Background(), &OrganizationsListOptions{Cursor: "2"})
+	it.SetBackward(true)
+
+	orgs, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Iterator.Collect returned error: %v", err)
+	}
+
+	if got, want := organizationIDs(orgs), []string{"c", "b", "a"}; !cmp.Equal(got, want) {
+		t.Errorf("Iterator.Collect returned %v, want %v", got, want)
+	}
+}
+
+func TestIterator_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/organizations", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"organizations": [{"id":"a"}], "metadata": {"next_cursor": "1"}}`)
+			return
+		}
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+	})
+
+	orgs, err := client.Organizations.ListAll(context.Background(), nil).Collect()
+	if err == nil {
+		t.Fatal("Expected HTTP 400 error, got no error.")
+	}
+	if got, want := organizationIDs(orgs), []string{"a"}; !cmp.Equal(got, want) {
+		t.Errorf("Iterator.Collect returned %v, want %v", got, want)
+	}
+}
+
+func TestIterator_canceledContext(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := setupPages(t, mux, "/api/v1/organizations", [][]string{{"a"}, {"b"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.Organizations.ListAll(ctx, nil)
+
+	if !it.Next() {
+		t.Fatalf("Iterator.Next returned false, error: %v", it.Err())
+	}
+	cancel()
+
+	if it.Next() {
+		t.Errorf("Iterator.Next returned true after cancellation")
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Iterator.Err = %v, want %v", it.Err(), context.Canceled)
+	}
+	if *calls != 1 {
+		t.Errorf("Iterator fetched %d pages, want 1", *calls)
+	}
+}
+
+func TestIterator_nilContext(t *testing.T) {
+	client := NewClient(nil)
+
+	//nolint:staticcheck //lint:ignore SA1012 we explicitly pass nil to test error
+	it := client.Organizations.ListAll(nil, nil)
+	if it.Next() {
+		t.Errorf("Iterator.Next returned true for nil context")
+	}
+	if !errors.Is(it.Err(), errNonNilContext) {
+		t.Errorf("Expected context must be non-nil error")
+	}
+}
@@ -0,0 +1,258 @@
+package gocancel
+
+import (
+	"context"
+)
+
+// LetterConfigSource identifies the level a value of a LetterConfig was taken
+// from.
+type LetterConfigSource string
+
+// The levels of a LetterConfig, from the most to the least specific.
+const (
+	LetterConfigSourceProductLocale      LetterConfigSource = "product_locale"
+	LetterConfigSourceProduct            LetterConfigSource = "product"
+	LetterConfigSourceOrganizationLocale LetterConfigSource = "organization_locale"
+	LetterConfigSourceOrganization       LetterConfigSource = "organization"
+	LetterConfigSourceCategoryLocale     LetterConfigSource = "category_locale"
+	LetterConfigSourceCategory           LetterConfigSource = "category"
+)
+
+// LetterConfig is the effective configuration of letters to an organization,
+// or one of its products, in a locale.
+type LetterConfig struct {
+	LetterTemplate    *LetterTemplate
+	Providers         []*LetterProvider
+	RequiresConsent   *bool
+	RequiresProofOfID *bool
+	Address           *Address
+	Email             *string
+	Fax               *string
+
+	// Sources explains which level each value was taken from.
+	Sources LetterConfigSources
+}
+
+func (c LetterConfig) String() string {
+	return Stringify(c)
+}
+
+func (c *LetterConfig) letterRequirements() (bool, bool) {
+	return c.RequiresConsent != nil && *c.RequiresConsent, c.RequiresProofOfID != nil && *c.RequiresProofOfID
+}
+
+// LetterConfigSources holds the level each value of a LetterConfig was taken
+// from. A source is empty if none of the levels has the value.
+type LetterConfigSources struct {
+	LetterTemplate    LetterConfigSource
+	Providers         LetterConfigSource
+	RequiresConsent   LetterConfigSource
+	RequiresProofOfID LetterConfigSource
+	Address           LetterConfigSource
+	Email             LetterConfigSource
+	Fax               LetterConfigSource
+}
+
+// LetterProvider represents a provider of a category, organization or product
+// locale.
+type LetterProvider struct {
+	ID     *string         `json:"id,omitempty"`
+	Name   *string         `json:"name,omitempty"`
+	Type   *ProviderType   `json:"type,omitempty"`
+	Method *ProviderMethod `json:"method,omitempty"`
+}
+
+func (p LetterProvider) String() string {
+	return Stringify(p)
+}
+
+// letterConfigLevel holds the letter configuration of a single level.
+type letterConfigLevel struct {
+	source            LetterConfigSource
+	letterTemplate    *LetterTemplate
+	providers         []*LetterProvider
+	requiresConsent   *bool
+	requiresProofOfID *bool
+	address           *Address
+	email             *string
+	fax               *string
+}
+
+// ResolveLetterConfig fetches an organization, the product if product is not
+// empty, and the category of the organization, and returns the effective
+// letter configuration in the given locale.
+//
+// Each value is taken from the most specific level that has it: the product
+// locale, the product, the organization locale, the organization, the
+// category locale and finally the category. Locales are negotiated like
+// Organization.LocaleFor does, so "nl-BE" falls back to "nl", except that a
+// level without a matching locale is skipped rather than falling back to its
+// first locale. The returned response is that of the last request made.
+func (s *OrganizationsService) ResolveLetterConfig(ctx context.Context, organization, product, locale string) (*LetterConfig, *Response, error) {
+	org, resp, err := s.Get(ctx, organization)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var p *Product
+	if product != "" {
+		p, resp, err = s.GetProduct(ctx, organization, product)
+		if err != nil {
+			return nil, resp, err
+		}
+	}
+
+	var category *Category
+	if org.CategoryID != nil && *org.CategoryID != "" {
+		category, resp, err = s.client.Categories.Get(ctx, *org.CategoryID)
+		if err != nil {
+			return nil, resp, err
+		}
+	}
+
+	return resolveLetterConfig(org, p, category, locale), resp, nil
+}
+
+// resolveLetterConfig returns the effective letter configuration of an
+// organization, and optionally a product and category, in a locale.
+func resolveLetterConfig(org *Organization, product *Product, category *Category, locale string) *LetterConfig {
+	var levels []letterConfigLevel
+	tags := []string{locale}
+
+	if product != nil {
+		if i := product.localeIndex(tags); i >= 0 {
+			levels = append(levels, product.Locales[i].letterConfigLevel())
+		}
+		levels = append(levels, letterConfigLevel{
+			source:            LetterConfigSourceProduct,
+			requiresConsent:   product.RequiresConsent,
+			requiresProofOfID: product.RequiresProofOfID,
+			address:           product.Address,
+			email:             product.Email,
+			fax:               product.Fax,
+		})
+	}
+
+	if org != nil {
+		if i := org.localeIndex(tags); i >= 0 {
+			levels = append(levels, org.Locales[i].letterConfigLevel())
+		}
+		levels = append(levels, letterConfigLevel{
+			source:            LetterConfigSourceOrganization,
+			requiresConsent:   org.RequiresConsent,
+			requiresProofOfID: org.RequiresProofOfID,
+			address:           org.Address,
+			email:             org.Email,
+			fax:               org.Fax,
+		})
+	}
+
+	if category != nil {
+		if i := category.localeIndex(tags); i >= 0 {
+			levels = append(levels, category.Locales[i].letterConfigLevel())
+		}
+		levels = append(levels, letterConfigLevel{
+			source:          LetterConfigSourceCategory,
+			requiresConsent: category.RequiresConsent,
+		})
+	}
+
+	c := new(LetterConfig)
+	for _, l := range levels {
+		if c.LetterTemplate == nil && l.letterTemplate != nil {
+			c.LetterTemplate, c.Sources.LetterTemplate = l.letterTemplate, l.source
+		}
+		if c.Providers == nil && len(l.providers) > 0 {
+			c.Providers, c.Sources.Providers = l.providers, l.source
+		}
+		if c.RequiresConsent == nil && l.requiresConsent != nil {
+			c.RequiresConsent, c.Sources.RequiresConsent = l.requiresConsent, l.source
+		}
+		if c.RequiresProofOfID == nil && l.requiresProofOfID != nil {
+			c.RequiresProofOfID, c.Sources.RequiresProofOfID = l.requiresProofOfID, l.source
+		}
+		if c.Address == nil && l.address != nil {
+			c.Address, c.Sources.Address = l.address, l.source
+		}
+		if c.Email == nil && l.email != nil && *l.email != "" {
+			c.Email, c.Sources.Email = l.email, l.source
+		}
+		if c.Fax == nil && l.fax != nil && *l.fax != "" {
+			c.Fax, c.Sources.Fax = l.fax, l.source
+		}
+	}
+
+	return c
+}
+
+// letterConfigLevel returns the letter configuration of the product locale.
+func (l *ProductLocale) letterConfigLevel() letterConfigLevel {
+	providers := make([]*LetterProvider, 0, len(l.Providers))
+	for _, p := range l.Providers {
+		if p != nil {
+			providers = append(providers, p.letterProvider())
+		}
+	}
+
+	return letterConfigLevel{
+		source:            LetterConfigSourceProductLocale,
+		letterTemplate:    l.LetterTemplate,
+		providers:         providers,
+		requiresConsent:   l.RequiresConsent,
+		requiresProofOfID: l.RequiresProofOfID,
+		address:           l.Address,
+		email:             l.Email,
+		fax:               l.Fax,
+	}
+}
+
+// letterConfigLevel returns the letter configuration of the organization
+// locale.
+func (l *OrganizationLocale) letterConfigLevel() letterConfigLevel {
+	providers := make([]*LetterProvider, 0, len(l.Providers))
+	for _, p := range l.Providers {
+		if p != nil {
+			providers = append(providers, p.letterProvider())
+		}
+	}
+
+	return letterConfigLevel{
+		source:            LetterConfigSourceOrganizationLocale,
+		letterTemplate:    l.LetterTemplate,
+		providers:         providers,
+		requiresConsent:   l.RequiresConsent,
+		requiresProofOfID: l.RequiresProofOfID,
+		address:           l.Address,
+		email:             l.Email,
+		fax:               l.Fax,
+	}
+}
+
+// letterConfigLevel returns the letter configuration of the category locale.
+func (l *CategoryLocale) letterConfigLevel() letterConfigLevel {
+	providers := make([]*LetterProvider, 0, len(l.Providers))
+	for _, p := range l.Providers {
+		if p != nil {
+			providers = append(providers, p.letterProvider())
+		}
+	}
+
+	return letterConfigLevel{
+		source:          LetterConfigSourceCategoryLocale,
+		letterTemplate:  l.LetterTemplate,
+		providers:       providers,
+		requiresConsent: l.RequiresConsent,
+	}
+}
+
+func (p *ProductProvider) letterProvider() *LetterProvider {
+	return &LetterProvider{ID: p.ID, Name: p.Name, Type: p.Type, Method: p.Method}
+}
+
+func (p *OrganizationProvider) letterProvider() *LetterProvider {
+	return &LetterProvider{ID: p.ID, Name: p.Name, Type: p.Type, Method: p.Method}
+}
+
+func (p *CategoryProvider) letterProvider() *LetterProvider {
+	return &LetterProvider{ID: p.ID, Name: p.Name, Type: p.Type, Method: p.Method}
+}
@@ -0,0 +1,118 @@
+package gocancel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOrganizationsService_ResolveLetterConfig(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/organizations/a", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"organization":{"id":"a","category_id":"c","email":"org@example.com","fax":"+31101234567","requires_consent":false,"requires_proof_of_id":false,"locales":[
+			{"locale":"nl-NL","email":"org-nl@example.com","requires_proof_of_id":true,"letter_template":{"template":"org"}},
+			{"locale":"en-US","email":"org-en@example.com","letter_template":{"template":"org-en"}}
+		]}}`)
+	})
+	mux.HandleFunc("/api/v1/organizations/a/products/b", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"product":{"id":"b","address":{"locality":"Utrecht"},"locales":[
+			{"locale":"nl-NL","letter_template":{"template":"product"}}
+		]}}`)
+	})
+	mux.HandleFunc("/api/v1/categories/c", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"category":{"id":"c","requires_consent":true,"locales":[
+			{"locale":"nl-NL","requires_consent":true,"providers":[{"id":"p","type":"email"}],"letter_template":{"template":"category"}}
+		]}}`)
+	})
+
+	ctx := context.Background()
+	got, _, err := client.Organizations.ResolveLetterConfig(ctx, "a", "b", "NL-nl")
+	if err != nil {
+		t.Fatalf("Organizations.ResolveLetterConfig returned error: %v", err)
+	}
+
+	want := &LetterConfig{
+		LetterTemplate:    &LetterTemplate{Template: String("product")},
+		Providers:         []*LetterProvider{{ID: String("p"), Type: ProviderTypeEmail.Ptr()}},
+		RequiresConsent:   Bool(false),
+		RequiresProofOfID: Bool(true),
+		Address:           &Address{Locality: String("Utrecht")},
+		Email:             String("org-nl@example.com"),
+		Fax:               String("+31101234567"),
+		Sources: LetterConfigSources{
+			LetterTemplate:    LetterConfigSourceProductLocale,
+			Providers:         LetterConfigSourceCategoryLocale,
+			RequiresConsent:   LetterConfigSourceOrganization,
+			RequiresProofOfID: LetterConfigSourceOrganizationLocale,
+			Address:           LetterConfigSourceProduct,
+			Email:             LetterConfigSourceOrganizationLocale,
+			Fax:               LetterConfigSourceOrganization,
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Organizations.ResolveLetterConfig returned %+v, want %+v", got, want)
+	}
+
+	got, _, err = client.Organizations.ResolveLetterConfig(ctx, "a", "", "en-US")
+	if err != nil {
+		t.Fatalf("Organizations.ResolveLetterConfig returned error: %v", err)
+	}
+	if got.LetterTemplate == nil || *got.LetterTemplate.Template != "org-en" {
+		t.Errorf("Organizations.ResolveLetterConfig returned letter template %v, want %q", got.LetterTemplate, "org-en")
+	}
+	if got.Providers != nil || got.Sources.Providers != "" {
+		t.Errorf("Organizations.ResolveLetterConfig returned providers %v from %q, want none", got.Providers, got.Sources.Providers)
+	}
+
+	got, _, err = client.Organizations.ResolveLetterConfig(ctx, "a", "b", "nl-BE")
+	if err != nil {
+		t.Fatalf("Organizations.ResolveLetterConfig returned error: %v", err)
+	}
+	if got.Sources.LetterTemplate != LetterConfigSourceProductLocale {
+		t.Errorf("Organizations.ResolveLetterConfig returned letter template from %q, want %q", got.Sources.LetterTemplate, LetterConfigSourceProductLocale)
+	}
+
+	const methodName = "ResolveLetterConfig"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Organizations.ResolveLetterConfig(ctx, "\n", "b", "nl-NL")
+		return err
+	})
+}
+
+func TestOrganizationsService_ResolveLetterConfig_productNotFound(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/organizations/a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"organization":{"id":"a"}}`)
+	})
+	mux.HandleFunc("/api/v1/organizations/a/products/b", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"not_found","message":"Product not found"}}`)
+	})
+
+	_, resp, err := client.Organizations.ResolveLetterConfig(context.Background(), "a", "b", "nl-NL")
+	if err == nil {
+		t.Fatal("Organizations.ResolveLetterConfig returned no error")
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Organizations.ResolveLetterConfig returned response %v, want a 404 response", resp)
+	}
+}
+
+func TestValidateLetterRequest_letterConfig(t *testing.T) {
+	config := &LetterConfig{RequiresConsent: Bool(true)}
+
+	err := ValidateLetterRequest(&LetterRequest{OrganizationID: "a"}, nil, config)
+	if errs, ok := err.(FieldErrors); !ok || len(errs.Field("consent")) != 1 {
+		t.Errorf("ValidateLetterRequest returned %v, want a consent error", err)
+	}
+}
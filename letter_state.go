@@ -0,0 +1,162 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// letterTransitions holds the states a letter in a given state can move to.
+//
+//	created ──▶ generating ──▶ drafted ──▶ sent
+//	   │             │            │
+//	   └─────────────┴────────────┴──────▶ failed
+//
+// A letter is created, after which its document is generated. It is then
+// sent by its provider, or drafted for the customer to send it themselves,
+// which happens when creating the letter with LetterRequest.Drafted set or
+// calling LettersService.MarkAsDrafted. Sent and failed letters are final.
+var letterTransitions = map[LetterState][]LetterState{
+	LetterStateCreated:    {LetterStateGenerating, LetterStateDrafted, LetterStateSent, LetterStateFailed},
+	LetterStateGenerating: {LetterStateDrafted, LetterStateSent, LetterStateFailed},
+	LetterStateDrafted:    {LetterStateSent, LetterStateFailed},
+	LetterStateSent:       nil,
+	LetterStateFailed:     nil,
+}
+
+// ErrLetterStateUnreachable is returned by LettersService.WaitForState when
+// the letter can no longer reach any of the target states, e.g. because it
+// has failed.
+var ErrLetterStateUnreachable = errors.New("letter can no longer reach the target state")
+
+// Transitions returns the states a letter in state s can move to next. It
+// returns nil for terminal and unknown states.
+func (s LetterState) Transitions() []LetterState {
+	return append([]LetterState(nil), letterTransitions[s]...)
+}
+
+// CanTransitionTo reports whether a letter in state s can move to state t
+// directly. Transitions from or to unknown states are reported as illegal.
+func (s LetterState) CanTransitionTo(t LetterState) bool {
+	for _, next := range letterTransitions[s] {
+		if next == t {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReach reports whether a letter in state s can eventually move to state
+// t, through any number of transitions. A state can reach itself.
+func (s LetterState) CanReach(t LetterState) bool {
+	seen := map[LetterState]bool{s: true}
+	queue := []LetterState{s}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if state == t {
+			return true
+		}
+
+		for _, next := range letterTransitions[state] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return false
+}
+
+// LetterTransition is a change of the state of a letter observed by
+// LettersService.WaitForState.
+type LetterTransition struct {
+	From   LetterState
+	To     LetterState
+	Letter *Letter
+}
+
+// PollPolicy configures how LettersService.WaitForState polls a letter.
+type PollPolicy struct {
+	// MinInterval is the interval before the second poll. Subsequent polls
+	// double the interval until MaxInterval is reached.
+	MinInterval time.Duration
+
+	// MaxInterval is the upper bound of the interval between two polls.
+	MaxInterval time.Duration
+
+	// Transitions optionally receives every observed change of the state of
+	// the letter. Sends block until received or the context is done.
+	Transitions chan<- LetterTransition
+}
+
+// DefaultPollPolicy is a sensible poll policy for waiting on letters to be
+// processed.
+var DefaultPollPolicy = PollPolicy{
+	MinInterval: time.Second,
+	MaxInterval: 30 * time.Second,
+}
+
+// WaitForState polls a letter until it reaches one of the target states and
+// returns it. Polling stops early with ErrLetterStateUnreachable once the
+// letter is in a known state from which none of the targets can be reached,
+// such as "failed" when waiting for "sent"; the letter is returned along with
+// the error. Letters in unknown states, and letters awaited to reach an
+// unknown state, are polled until the context is done.
+//
+// The interval between polls backs off according to policy, which defaults
+// to DefaultPollPolicy when nil. Use a context with a deadline to bound the
+// total wait.
+func (s *LettersService) WaitForState(ctx context.Context, letter string, targets []LetterState, policy *PollPolicy) (*Letter, *Response, error) {
+	if len(targets) == 0 {
+		return nil, nil, errors.New("no target letter states")
+	}
+	if policy == nil {
+		policy = &DefaultPollPolicy
+	}
+	backoff := &RetryPolicy{MinBackoff: policy.MinInterval, MaxBackoff: policy.MaxInterval}
+	if backoff.MaxBackoff < backoff.MinBackoff {
+		backoff.MaxBackoff = backoff.MinBackoff
+	}
+
+	var previous LetterState
+	for poll := 1; ; poll++ {
+		l, resp, err := s.Get(ctx, letter)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		var state LetterState
+		if l.State != nil {
+			state = *l.State
+		}
+
+		if poll > 1 && state != previous && policy.Transitions != nil {
+			select {
+			case policy.Transitions <- LetterTransition{From: previous, To: state, Letter: l}:
+			case <-ctx.Done():
+				return nil, resp, ctx.Err()
+			}
+		}
+		previous = state
+
+		reachable := !state.IsKnown()
+		for _, t := range targets {
+			if state == t {
+				return l, resp, nil
+			}
+			if !t.IsKnown() || state.CanReach(t) {
+				reachable = true
+			}
+		}
+		if !reachable {
+			return l, resp, fmt.Errorf("%w: letter %s is %s", ErrLetterStateUnreachable, letter, state)
+		}
+
+		if err := sleep(ctx, backoff.backoff(poll)); err != nil {
+			return nil, resp, err
+		}
+	}
+}
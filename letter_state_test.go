@@ -0,0 +1,141 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLetterState_transitions(t *testing.T) {
+	if !LetterStateCreated.CanTransitionTo(LetterStateGenerating) {
+		t.Error("created cannot transition to generating")
+	}
+	if LetterStateSent.CanTransitionTo(LetterStateFailed) {
+		t.Error("sent can transition to failed")
+	}
+	if LetterState("archived").CanTransitionTo(LetterStateSent) {
+		t.Error("an unknown state can transition to sent")
+	}
+
+	if got := LetterStateFailed.Transitions(); got != nil {
+		t.Errorf("Transitions of failed = %v, want none", got)
+	}
+
+	if !LetterStateCreated.CanReach(LetterStateSent) || !LetterStateSent.CanReach(LetterStateSent) {
+		t.Error("CanReach returned false for a reachable state")
+	}
+	if LetterStateDrafted.CanReach(LetterStateGenerating) || LetterStateFailed.CanReach(LetterStateSent) {
+		t.Error("CanReach returned true for an unreachable state")
+	}
+
+	for state := range letterTransitions {
+		if got, want := len(state.Transitions()) == 0, state.IsTerminal(); got != want {
+			t.Errorf("State %q has no transitions = %t, but IsTerminal = %t", state, got, want)
+		}
+	}
+}
+
+// serveLetterStates serves the letter "b" in the given states, one per
+// request, repeating the last state.
+func serveLetterStates(t *testing.T, mux *http.ServeMux, states ...LetterState) *int {
+	requests := 0
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		state := states[len(states)-1]
+		if requests < len(states) {
+			state = states[requests]
+		}
+		requests++
+		fmt.Fprintf(w, `{"letter":{"id":"b","state":%q}}`, state)
+	})
+
+	return &requests
+}
+
+var testPollPolicy = PollPolicy{MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+func TestLettersService_WaitForState(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	requests := serveLetterStates(t, mux, LetterStateCreated, LetterStateGenerating, LetterStateGenerating, LetterStateSent)
+
+	transitions := make(chan LetterTransition, 10)
+	policy := testPollPolicy
+	policy.Transitions = transitions
+
+	ctx := context.Background()
+	letter, _, err := client.Letters.WaitForState(ctx, "b", []LetterState{LetterStateSent, LetterStateDrafted}, &policy)
+	if err != nil {
+		t.Fatalf("Letters.WaitForState returned error: %v", err)
+	}
+	if *letter.State != LetterStateSent {
+		t.Errorf("Letters.WaitForState returned state %q, want %q", *letter.State, LetterStateSent)
+	}
+	if *requests != 4 {
+		t.Errorf("Letters.WaitForState made %d requests, want 4", *requests)
+	}
+
+	close(transitions)
+	var got [][2]LetterState
+	for tr := range transitions {
+		got = append(got, [2]LetterState{tr.From, tr.To})
+	}
+	want := [][2]LetterState{{LetterStateCreated, LetterStateGenerating}, {LetterStateGenerating, LetterStateSent}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Letters.WaitForState observed transitions %v, want %v", got, want)
+	}
+
+	const methodName = "WaitForState"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Letters.WaitForState(ctx, "\n", []LetterState{LetterStateSent}, &policy)
+		return err
+	})
+}
+
+func TestLettersService_WaitForState_failed(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	requests := serveLetterStates(t, mux, LetterStateGenerating, LetterStateFailed, LetterStateSent)
+
+	letter, _, err := client.Letters.WaitForState(context.Background(), "b", []LetterState{LetterStateSent}, &testPollPolicy)
+	if !errors.Is(err, ErrLetterStateUnreachable) {
+		t.Errorf("Letters.WaitForState returned %v, want %v", err, ErrLetterStateUnreachable)
+	}
+	if letter == nil || *letter.State != LetterStateFailed {
+		t.Errorf("Letters.WaitForState returned letter %v, want a failed letter", letter)
+	}
+	if *requests != 2 {
+		t.Errorf("Letters.WaitForState made %d requests, want 2", *requests)
+	}
+}
+
+func TestLettersService_WaitForState_timeout(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	serveLetterStates(t, mux, LetterStateGenerating)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.Letters.WaitForState(ctx, "b", []LetterState{LetterStateSent}, &testPollPolicy)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Letters.WaitForState returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLettersService_WaitForState_noTargets(t *testing.T) {
+	client, _, _, teardown := setup()
+	defer teardown()
+
+	if _, _, err := client.Letters.WaitForState(context.Background(), "b", nil, nil); err == nil {
+		t.Error("Letters.WaitForState returned no error without target states")
+	}
+}
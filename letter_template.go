@@ -1,5 +1,15 @@
 package gocancel
 
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // LetterTemplate represents an embeddable letter template.
 type LetterTemplate struct {
 	Template *string                `json:"template,omitempty"`
@@ -24,3 +34,164 @@ type LetterTemplateFieldOption struct {
 	Value *string `json:"value,omitempty"`
 	Label *string `json:"label,omitempty"`
 }
+
+var (
+	// placeholderRE matches the placeholders of a letter template, such as
+	// "{{ name }}".
+	placeholderRE = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+	// paragraphRE matches the blank lines separating paragraphs.
+	paragraphRE = regexp.MustCompile(`\n\s*\n`)
+)
+
+// RenderedLetter is a letter template rendered with letter parameters.
+type RenderedLetter struct {
+	// Text is the rendered letter as plain text.
+	Text string
+
+	// HTML is the rendered letter as an HTML fragment. Paragraphs are
+	// separated by blank lines in the template.
+	HTML string
+
+	// Missing holds the keys of the placeholders and required fields that
+	// have neither a parameter nor a default value. Their placeholders are
+	// left as is in the rendered letter.
+	Missing []string
+
+	// Unknown holds the keys of the parameters that are neither fields nor
+	// placeholders of the template, sorted.
+	Unknown []string
+}
+
+// Render substitutes params into the template, like GoCancel does when
+// drafting a letter, so the letter can be previewed before it is created.
+// Placeholders without a parameter fall back to the default of their field,
+// and values of fields with options are replaced by the label of the
+// matching option.
+func (l *LetterTemplate) Render(params LetterParameters) (*RenderedLetter, error) {
+	if l == nil || l.Template == nil {
+		return nil, errors.New("letter template has no template")
+	}
+
+	fields := make(map[string]*LetterTemplateField, len(l.Fields))
+	for _, f := range l.Fields {
+		if f != nil && f.Key != nil {
+			fields[*f.Key] = f
+		}
+	}
+
+	r := new(RenderedLetter)
+	missing := make(map[string]bool)
+	addMissing := func(key string) {
+		if !missing[key] {
+			missing[key] = true
+			r.Missing = append(r.Missing, key)
+		}
+	}
+
+	var text, markup strings.Builder
+	last := 0
+	for _, m := range placeholderRE.FindAllStringSubmatchIndex(*l.Template, -1) {
+		literal := (*l.Template)[last:m[0]]
+		text.WriteString(literal)
+		markup.WriteString(html.EscapeString(literal))
+		last = m[1]
+
+		key := (*l.Template)[m[2]:m[3]]
+		value, ok := fieldValue(fields[key], params, key)
+		if !ok {
+			addMissing(key)
+			value = (*l.Template)[m[0]:m[1]]
+		}
+
+		text.WriteString(value)
+		markup.WriteString(html.EscapeString(value))
+	}
+	text.WriteString((*l.Template)[last:])
+	markup.WriteString(html.EscapeString((*l.Template)[last:]))
+
+	for _, f := range l.Fields {
+		if f == nil || f.Key == nil || f.Required == nil || !*f.Required {
+			continue
+		}
+		if _, ok := fieldValue(f, params, *f.Key); !ok {
+			addMissing(*f.Key)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, m := range placeholderRE.FindAllStringSubmatch(*l.Template, -1) {
+		used[m[1]] = true
+	}
+	for key := range params {
+		if !used[key] && fields[key] == nil {
+			r.Unknown = append(r.Unknown, key)
+		}
+	}
+	sort.Strings(r.Unknown)
+
+	r.Text = text.String()
+	r.HTML = paragraphs(markup.String())
+
+	return r, nil
+}
+
+// fieldValue returns the display value of the field with the given key, taken
+// from params or the default of the field.
+func fieldValue(f *LetterTemplateField, params LetterParameters, key string) (string, bool) {
+	var value string
+	if v, ok := params[key]; ok && v != nil {
+		value = formatParameter(v)
+	} else if f != nil && f.Default != nil {
+		value = *f.Default
+	} else {
+		return "", false
+	}
+
+	if f != nil {
+		for _, o := range f.Options {
+			if o != nil && o.Value != nil && *o.Value == value && o.Label != nil {
+				return *o.Label, true
+			}
+		}
+	}
+
+	return value, true
+}
+
+// formatParameter formats a parameter value, as decoded from JSON or set by
+// the caller, for display.
+func formatParameter(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = formatParameter(v[i])
+		}
+		return strings.Join(values, ", ")
+	}
+
+	return fmt.Sprint(v)
+}
+
+// paragraphs wraps the blank line separated paragraphs of escaped text in
+// <p> elements, breaking the remaining lines with <br>.
+func paragraphs(escaped string) string {
+	escaped = strings.ReplaceAll(escaped, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, p := range paragraphRE.Split(strings.TrimSpace(escaped), -1) {
+		if p == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(p, "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+
+	return b.String()
+}
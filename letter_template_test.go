@@ -0,0 +1,78 @@
+package gocancel
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLetterTemplate_Render(t *testing.T) {
+	template := &LetterTemplate{
+		Template: String("Dear {{ name }},\n\nPlease cancel my {{plan}} subscription\nper {{ date }}.\n\nKind regards,\n{{ name }} <{{ email }}>"),
+		Fields: []*LetterTemplateField{
+			{Key: String("name"), Type: String("string"), Required: Bool(true)},
+			{Key: String("email"), Type: String("email"), Required: Bool(true)},
+			{Key: String("date"), Type: String("date"), Default: String("today")},
+			{
+				Key:  String("plan"),
+				Type: String("select"),
+				Options: []*LetterTemplateFieldOption{
+					{Value: String("basic"), Label: String("Basic")},
+					{Value: String("pro"), Label: String("Professional")},
+				},
+			},
+			{Key: String("customer_number"), Type: String("number"), Required: Bool(true)},
+		},
+	}
+
+	got, err := template.Render(LetterParameters{
+		"name":  "Jane & John",
+		"plan":  "pro",
+		"extra": 1.5,
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	want := &RenderedLetter{
+		Text: "Dear Jane & John,\n\nPlease cancel my Professional subscription\nper today.\n\nKind regards,\nJane & John <{{ email }}>",
+		HTML: "<p>Dear Jane &amp; John,</p>\n" +
+			"<p>Please cancel my Professional subscription<br>\nper today.</p>\n" +
+			"<p>Kind regards,<br>\nJane &amp; John &lt;{{ email }}&gt;</p>\n",
+		Missing: []string{"email", "customer_number"},
+		Unknown: []string{"extra"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Render returned diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestLetterTemplate_Render_noTemplate(t *testing.T) {
+	if _, err := (&LetterTemplate{}).Render(nil); err == nil {
+		t.Error("Render returned no error for a letter template without template")
+	}
+
+	var template *LetterTemplate
+	if _, err := template.Render(nil); err == nil {
+		t.Error("Render returned no error for a nil letter template")
+	}
+}
+
+func TestFormatParameter(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"foo", "foo"},
+		{float64(12), "12"},
+		{1.25, "1.25"},
+		{true, "true"},
+		{[]interface{}{"a", float64(2)}, "a, 2"},
+	}
+
+	for _, tt := range tests {
+		if got := formatParameter(tt.value); got != tt.want {
+			t.Errorf("formatParameter(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
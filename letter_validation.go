@@ -0,0 +1,194 @@
+package gocancel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The codes of the field errors reported by ValidateLetterRequest.
+const (
+	FieldErrorRequired      = "required"
+	FieldErrorInvalidOption = "invalid_option"
+	FieldErrorInvalidFormat = "invalid_format"
+)
+
+// LetterRecipient is an organization or product that letters are addressed
+// to, or one of their locales. It determines whether letters require the
+// consent of the customer and a proof of ID. It is implemented by
+// *Organization, *OrganizationLocale, *Product, *ProductLocale and *LetterConfig.
+type LetterRecipient interface {
+	letterRequirements() (consent, proofOfID bool)
+}
+
+func (o *Organization) letterRequirements() (bool, bool) {
+	return o.RequiresConsent != nil && *o.RequiresConsent, o.RequiresProofOfID != nil && *o.RequiresProofOfID
+}
+
+func (o *OrganizationLocale) letterRequirements() (bool, bool) {
+	return o.RequiresConsent != nil && *o.RequiresConsent, o.RequiresProofOfID != nil && *o.RequiresProofOfID
+}
+
+func (p *Product) letterRequirements() (bool, bool) {
+	return p.RequiresConsent != nil && *p.RequiresConsent, p.RequiresProofOfID != nil && *p.RequiresProofOfID
+}
+
+func (p *ProductLocale) letterRequirements() (bool, bool) {
+	return p.RequiresConsent != nil && *p.RequiresConsent, p.RequiresProofOfID != nil && *p.RequiresProofOfID
+}
+
+var errLetterRequestNil = errors.New("letter request is nil")
+
+var phoneRE = regexp.MustCompile(`^\+?[0-9][0-9 ()./\-]{4,}[0-9]$`)
+
+// ValidateLetterRequest checks req against the letter template and the
+// requirements of the recipient of the letter, before sending it to the API.
+// It checks that required parameters are present, that parameters of fields
+// with options have one of the option values, and that parameters match the
+// type of their field, e.g. "date", "email", "phone", "number", "boolean" or
+// "url". Fields of other types accept any value. The signature type must be
+// one of the known signature types. Consent and a proof of ID are checked
+// when the recipient requires them. Both template and recipient
+// are optional.
+//
+// The returned error is nil if req is valid, and FieldErrors otherwise. Field
+// errors refer to parameters as "parameters.<key>". A nil req is reported as
+// an error rather than a panic.
+func ValidateLetterRequest(req *LetterRequest, template *LetterTemplate, recipient LetterRecipient) error {
+	if req == nil {
+		return errLetterRequestNil
+	}
+
+	var errs FieldErrors
+	add := func(field, code, msg string) {
+		errs = append(errs, &FieldError{Field: field, Code: code, Message: msg})
+	}
+
+	if req.OrganizationID == "" {
+		add("organization_id", FieldErrorRequired, "is required")
+	}
+
+	if template != nil {
+		for _, f := range template.Fields {
+			if f == nil || f.Key == nil {
+				continue
+			}
+
+			field := "parameters." + *f.Key
+			v, ok := req.Parameters[*f.Key]
+			if !ok || v == nil || v == "" {
+				if f.Required != nil && *f.Required && f.Default == nil {
+					add(field, FieldErrorRequired, "is required")
+				}
+				continue
+			}
+
+			if len(f.Options) > 0 && !hasOption(f, formatParameter(v)) {
+				add(field, FieldErrorInvalidOption, fmt.Sprintf("must be one of %s", strings.Join(optionValues(f), ", ")))
+				continue
+			}
+
+			if f.Type != nil {
+				if msg := checkFieldType(*f.Type, v); msg != "" {
+					add(field, FieldErrorInvalidFormat, msg)
+				}
+			}
+		}
+	}
+
+	if fe := checkSignatureType(req.SignatureType); fe != nil {
+		errs = append(errs, fe)
+	}
+
+	if recipient != nil {
+		consent, proofOfID := recipient.letterRequirements()
+		if consent && !req.Consent {
+			add("consent", FieldErrorRequired, "is required")
+		}
+		if proofOfID && len(req.ProofOfIDs) == 0 {
+			add("proof_of_ids", FieldErrorRequired, "a proof of ID is required")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func hasOption(f *LetterTemplateField, value string) bool {
+	for _, o := range f.Options {
+		if o != nil && o.Value != nil && *o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func optionValues(f *LetterTemplateField) []string {
+	var values []string
+	for _, o := range f.Options {
+		if o != nil && o.Value != nil {
+			values = append(values, strconv.Quote(*o.Value))
+		}
+	}
+	return values
+}
+
+// checkFieldType returns why v is not a valid value for a field of type t, or
+// an empty string if it is.
+func checkFieldType(t string, v interface{}) string {
+	s, isString := v.(string)
+
+	switch t {
+	case "number":
+		switch v := v.(type) {
+		case float64, float32, int, int32, int64, json.Number:
+			return ""
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return ""
+			}
+		}
+		return "must be a number"
+	case "boolean", "checkbox":
+		if _, ok := v.(bool); ok {
+			return ""
+		}
+		if _, err := strconv.ParseBool(s); isString && err == nil {
+			return ""
+		}
+		return "must be a boolean"
+	case "date":
+		if _, ok := v.(time.Time); ok {
+			return ""
+		}
+		if _, err := time.Parse("2006-01-02", s); isString && err == nil {
+			return ""
+		}
+		return "must be a date formatted as YYYY-MM-DD"
+	case "email":
+		if a, err := mail.ParseAddress(s); isString && err == nil && a.Address == s {
+			return ""
+		}
+		return "must be an email address"
+	case "phone":
+		if isString && phoneRE.MatchString(s) {
+			return ""
+		}
+		return "must be a phone number"
+	case "url":
+		if u, err := url.Parse(s); isString && err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+			return ""
+		}
+		return "must be an http or https URL"
+	}
+
+	return ""
+}
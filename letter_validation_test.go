@@ -0,0 +1,126 @@
+package gocancel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testValidationTemplate = &LetterTemplate{
+	Template: String("Dear {{ name }}"),
+	Fields: []*LetterTemplateField{
+		{Key: String("name"), Type: String("string"), Required: Bool(true)},
+		{Key: String("country"), Type: String("string"), Required: Bool(true), Default: String("NL")},
+		{Key: String("customer_number"), Type: String("number")},
+		{Key: String("date"), Type: String("date")},
+		{Key: String("email"), Type: String("email")},
+		{Key: String("phone"), Type: String("phone")},
+		{Key: String("website"), Type: String("url")},
+		{Key: String("newsletter"), Type: String("boolean")},
+		{
+			Key:  String("plan"),
+			Type: String("select"),
+			Options: []*LetterTemplateFieldOption{
+				{Value: String("basic"), Label: String("Basic")},
+				{Value: String("pro"), Label: String("Professional")},
+			},
+		},
+	},
+}
+
+func TestValidateLetterRequest_valid(t *testing.T) {
+	req := &LetterRequest{
+		OrganizationID: "a",
+		Consent:        true,
+		ProofOfIDs:     []string{"p"},
+		Parameters: LetterParameters{
+			"name":            "Jane Doe",
+			"customer_number": float64(12345),
+			"date":            "2021-05-27",
+			"email":           "jane@example.com",
+			"phone":           "+31 (0)20-123 4567",
+			"website":         "https://example.com",
+			"newsletter":      false,
+			"plan":            "pro",
+		},
+	}
+	recipient := &Product{RequiresConsent: Bool(true), RequiresProofOfID: Bool(true)}
+
+	if err := ValidateLetterRequest(req, testValidationTemplate, recipient); err != nil {
+		t.Errorf("ValidateLetterRequest returned error: %v", err)
+	}
+
+	if err := ValidateLetterRequest(&LetterRequest{OrganizationID: "a"}, nil, nil); err != nil {
+		t.Errorf("ValidateLetterRequest without template returned error: %v", err)
+	}
+}
+
+func TestValidateLetterRequest_invalid(t *testing.T) {
+	req := &LetterRequest{
+		Parameters: LetterParameters{
+			"customer_number": "12a",
+			"date":            "27-05-2021",
+			"email":           "Jane <jane@example.com>",
+			"phone":           "call me",
+			"website":         "ftp://example.com",
+			"newsletter":      "maybe",
+			"plan":            "enterprise",
+		},
+		SignatureType: "stamp",
+	}
+	recipient := &OrganizationLocale{RequiresConsent: Bool(true), RequiresProofOfID: Bool(true)}
+
+	err := ValidateLetterRequest(req, testValidationTemplate, recipient)
+
+	var errs FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ValidateLetterRequest returned %v, want FieldErrors", err)
+	}
+
+	type fieldCode struct{ Field, Code string }
+	var got []fieldCode
+	for _, fe := range errs {
+		got = append(got, fieldCode{fe.Field, fe.Code})
+	}
+
+	want := []fieldCode{
+		{"organization_id", FieldErrorRequired},
+		{"parameters.name", FieldErrorRequired},
+		{"parameters.customer_number", FieldErrorInvalidFormat},
+		{"parameters.date", FieldErrorInvalidFormat},
+		{"parameters.email", FieldErrorInvalidFormat},
+		{"parameters.phone", FieldErrorInvalidFormat},
+		{"parameters.website", FieldErrorInvalidFormat},
+		{"parameters.newsletter", FieldErrorInvalidFormat},
+		{"parameters.plan", FieldErrorInvalidOption},
+		{"signature_type", FieldErrorInvalidOption},
+		{"consent", FieldErrorRequired},
+		{"proof_of_ids", FieldErrorRequired},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ValidateLetterRequest returned diff (-want +got):\n%s", diff)
+	}
+
+	if fes := errs.Field("parameters.plan"); len(fes) != 1 || fes[0].Message != `must be one of "basic", "pro"` {
+		t.Errorf("FieldErrors.Field returned %v", fes)
+	}
+}
+
+func TestValidateLetterRequest_nil(t *testing.T) {
+	if err := ValidateLetterRequest(nil, nil, nil); err == nil {
+		t.Error("ValidateLetterRequest returned no error for a nil request")
+	}
+}
+
+func TestFieldErrors_Error(t *testing.T) {
+	errs := FieldErrors{
+		{Field: "consent", Code: FieldErrorRequired, Message: "is required"},
+		{Field: "parameters.name", Code: FieldErrorRequired, Message: "is required"},
+	}
+
+	want := "consent: is required; parameters.name: is required"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error = %q, want %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 )
 
 // LettersService provides access to the letter related functions
@@ -21,14 +22,14 @@ type Letter struct {
 	ProviderID            *string                `json:"provider_id,omitempty"`
 	ProviderConfiguration *ProviderConfiguration `json:"provider_configuration,omitempty"`
 	Locale                *string                `json:"locale,omitempty"`
-	State                 *string                `json:"state,omitempty"`
+	State                 *LetterState           `json:"state,omitempty"`
 	ProofOfIDs            []*string              `json:"proof_of_ids,omitempty"`
 	Parameters            *LetterParameters      `json:"parameters,omitempty"`
 	Email                 *string                `json:"email,omitempty"`
 	Fax                   *string                `json:"fax,omitempty"`
 	Address               *Address               `json:"address,omitempty"`
 	LetterTemplate        *LetterTemplate        `json:"letter_template,omitempty"`
-	SignatureType         *string                `json:"signature_type,omitempty"`
+	SignatureType         *SignatureType         `json:"signature_type,omitempty"`
 	SignatureData         *string                `json:"signature_data,omitempty"`
 	SandboxMode           *bool                  `json:"sandbox_mode,omitempty"`
 	SandboxEmail          *string                `json:"sandbox_email,omitempty"`
@@ -41,6 +42,73 @@ func (l Letter) String() string {
 	return Stringify(l)
 }
 
+// LetterState represents the state of a letter, e.g. "sent". States unknown
+// to this package are preserved when decoding and encoding letters.
+type LetterState string
+
+// This block represents the known states of a letter.
+const (
+	LetterStateCreated    LetterState = "created"
+	LetterStateGenerating LetterState = "generating"
+	LetterStateDrafted    LetterState = "drafted"
+	LetterStateSent       LetterState = "sent"
+	LetterStateFailed     LetterState = "failed"
+)
+
+// IsKnown reports whether s is one of the known letter states.
+func (s LetterState) IsKnown() bool {
+	switch s {
+	case LetterStateCreated, LetterStateGenerating, LetterStateDrafted, LetterStateSent, LetterStateFailed:
+		return true
+	}
+	return false
+}
+
+// Ptr returns a pointer to s, for use in struct literals.
+func (s LetterState) Ptr() *LetterState { return &s }
+
+// IsTerminal reports whether s is a final state, after which the state of the
+// letter no longer changes.
+func (s LetterState) IsTerminal() bool {
+	return s == LetterStateSent || s == LetterStateFailed
+}
+
+// SignatureType represents the type of the signature of a letter. Types
+// unknown to this package are preserved when decoding and encoding letters.
+type SignatureType string
+
+// This block represents the known signature types of a letter.
+const (
+	// SignatureTypeText is a signature typed as text.
+	SignatureTypeText SignatureType = "text"
+
+	// SignatureTypeImage is a drawn signature, sent as a base64 encoded
+	// image.
+	SignatureTypeImage SignatureType = "image"
+)
+
+// IsKnown reports whether t is one of the known signature types.
+func (t SignatureType) IsKnown() bool {
+	return t == SignatureTypeText || t == SignatureTypeImage
+}
+
+// Ptr returns a pointer to t, for use in struct literals.
+func (t SignatureType) Ptr() *SignatureType { return &t }
+
+// checkSignatureType returns a field error if t is set but not one of the
+// known signature types.
+func checkSignatureType(t SignatureType) *FieldError {
+	if t == "" || t.IsKnown() {
+		return nil
+	}
+
+	return &FieldError{
+		Field:   "signature_type",
+		Code:    FieldErrorInvalidOption,
+		Message: fmt.Sprintf("must be one of %q, %q", SignatureTypeText, SignatureTypeImage),
+	}
+}
+
 // LetterParameters represents key-value parameters for a letter.
 type LetterParameters map[string]interface{}
 
@@ -52,7 +120,7 @@ type LetterRequest struct {
 	Locale         string           `json:"locale,omitempty"`
 	Parameters     LetterParameters `json:"parameters,omitempty"`
 	ProofOfIDs     []string         `json:"proof_of_ids,omitempty"`
-	SignatureType  string           `json:"signature_type,omitempty"`
+	SignatureType  SignatureType    `json:"signature_type,omitempty"`
 	SignatureData  string           `json:"signature_data,omitempty"`
 	Consent        bool             `json:"consent,omitempty"`
 	Metadata       AccountMetadata  `json:"metadata,omitempty"`
@@ -64,7 +132,39 @@ type LettersSortOptions struct {
 	UpdatedAt string `url:"updated_at,omitempty"`
 }
 
+// LettersTimeRangeOptions restricts a timestamp to a range. A zero bound is
+// not applied.
+type LettersTimeRangeOptions struct {
+	After  time.Time `url:"gte,omitempty"`
+	Before time.Time `url:"lte,omitempty"`
+}
+
+// LettersListOptions specifies the optional parameters to the
+// LettersService.List method.
 type LettersListOptions struct {
+	Cursor string `url:"cursor,omitempty"`
+	Limit  int    `url:"limit,omitempty"`
+
+	// States filters letters by one or more states.
+	States []LetterState `url:"states[],omitempty"`
+
+	// OrganizationIDs, ProductIDs and ProviderIDs filter letters by the
+	// organization, product and provider they are addressed to.
+	OrganizationIDs []string `url:"organization_ids[],omitempty"`
+	ProductIDs      []string `url:"product_ids[],omitempty"`
+	ProviderIDs     []string `url:"provider_ids[],omitempty"`
+
+	// Metadata filters letters by their metadata key-value pairs.
+	Metadata MetadataFilter `url:"metadata,omitempty"`
+
+	// CreatedAt and UpdatedAt filter letters by the time they were created
+	// and last updated.
+	CreatedAt LettersTimeRangeOptions `url:"created_at,omitempty"`
+	UpdatedAt LettersTimeRangeOptions `url:"updated_at,omitempty"`
+
+	// SandboxMode filters letters by whether they were created in sandbox mode.
+	SandboxMode *bool `url:"sandbox_mode,omitempty"`
+
 	Sort LettersSortOptions `url:"sort,omitempty"`
 }
 
@@ -79,6 +179,8 @@ type lettersRoot struct {
 
 // List lists all letters.
 func (s *LettersService) List(ctx context.Context, opts *LettersListOptions) ([]*Letter, *Response, error) {
+	ctx = withOperation(ctx, "Letters.List")
+
 	u, err := addOptions("api/v1/letters", opts)
 	if err != nil {
 		return nil, nil, err
@@ -100,8 +202,59 @@ func (s *LettersService) List(ctx context.Context, opts *LettersListOptions) ([]
 	return root.Letters, resp, nil
 }
 
-// Create creates a letter.
+// LettersIterator iterates over letters, following the pagination cursors
+// automatically.
+type LettersIterator struct {
+	iterator
+	page []*Letter
+}
+
+// Value returns the current letter. It must only be called after a call to
+// Next returned true.
+func (it *LettersIterator) Value() *Letter {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *LettersIterator) Collect() ([]*Letter, error) {
+	var items []*Letter
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAll returns an iterator over all letters, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *LettersService) ListAll(ctx context.Context, opts *LettersListOptions) *LettersIterator {
+	var o LettersListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(LettersIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.List(ctx, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
+// Create creates a letter. A request with an unknown signature type is
+// rejected with FieldErrors before it is sent.
 func (s *LettersService) Create(ctx context.Context, request *LetterRequest) (*Letter, *Response, error) {
+	ctx = withOperation(ctx, "Letters.Create")
+
+	if request != nil {
+		if fe := checkSignatureType(request.SignatureType); fe != nil {
+			return nil, nil, FieldErrors{fe}
+		}
+	}
+
 	req, err := s.client.NewRequest("POST", "api/v1/letters", request)
 	if err != nil {
 		return nil, nil, err
@@ -118,6 +271,8 @@ func (s *LettersService) Create(ctx context.Context, request *LetterRequest) (*L
 
 // Get fetches a letter.
 func (s *LettersService) Get(ctx context.Context, letter string) (*Letter, *Response, error) {
+	ctx = withOperation(ctx, "Letters.Get")
+
 	u := fmt.Sprintf("api/v1/letters/%s", letter)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -134,8 +289,17 @@ func (s *LettersService) Get(ctx context.Context, letter string) (*Letter, *Resp
 	return root.Letter, resp, nil
 }
 
-// Update updates a letter.
+// Update updates a letter. A request with an unknown signature type is
+// rejected with FieldErrors before it is sent.
 func (s *LettersService) Update(ctx context.Context, letter string, request *LetterRequest) (*Letter, *Response, error) {
+	ctx = withOperation(ctx, "Letters.Update")
+
+	if request != nil {
+		if fe := checkSignatureType(request.SignatureType); fe != nil {
+			return nil, nil, FieldErrors{fe}
+		}
+	}
+
 	u := fmt.Sprintf("api/v1/letters/%s", letter)
 	req, err := s.client.NewRequest("PUT", u, request)
 	if err != nil {
@@ -154,6 +318,8 @@ func (s *LettersService) Update(ctx context.Context, letter string, request *Let
 
 // Delete deletes a letter.
 func (s *LettersService) Delete(ctx context.Context, letter string) (*Response, error) {
+	ctx = withOperation(ctx, "Letters.Delete")
+
 	u := fmt.Sprintf("api/v1/letters/%s", letter)
 	req, err := s.client.NewRequest("DELETE", u, nil)
 	if err != nil {
@@ -172,6 +338,8 @@ func (s *LettersService) Delete(ctx context.Context, letter string) (*Response,
 
 // DownloadDocument fetches a letter document as binary stream.
 func (s *LettersService) DownloadDocument(ctx context.Context, letter string) (io.ReadCloser, *Response, error) {
+	ctx = withOperation(ctx, "Letters.DownloadDocument")
+
 	u := fmt.Sprintf("api/v1/letters/%s/document", letter)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -179,17 +347,31 @@ func (s *LettersService) DownloadDocument(ctx context.Context, letter string) (i
 	}
 	req.Header.Set("Accept", mediaTypeLetterDocument)
 
-	resp, err := s.client.client.Do(req)
+	resp, err := s.client.BareDo(ctx, req)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
 
-	if err := CheckResponse(resp); err != nil {
-		resp.Body.Close()
+	return resp.Body, resp, nil
+}
+
+// DownloadDocumentTo downloads a letter document into w, starting at offset
+// 0. When the transfer is interrupted, the download is resumed using an HTTP
+// Range request, so only the missing part of the document is transferred
+// again. If the API sends the whole document again instead, w is truncated
+// using its Truncate method, like that of *os.File, before it is rewritten;
+// the download fails if w has no such method.
+func (s *LettersService) DownloadDocumentTo(ctx context.Context, letter string, w io.WriterAt) (*Download, *Response, error) {
+	ctx = withOperation(ctx, "Letters.DownloadDocumentTo")
+
+	u := fmt.Sprintf("api/v1/letters/%s/document", letter)
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
 		return nil, nil, err
 	}
+	req.Header.Set("Accept", mediaTypeLetterDocument)
 
-	return resp.Body, newResponse(resp), nil
+	return s.client.downloadTo(ctx, req, w)
 }
 
 // MarkLetterAsDraftedRequest represents a `mark letter as drafted` request.
@@ -199,6 +381,8 @@ type MarkLetterAsDraftedRequest struct {
 
 // MarkAsDrafted marks a letter as drafted.
 func (s *LettersService) MarkAsDrafted(ctx context.Context, letter string, request *MarkLetterAsDraftedRequest) (*Letter, *Response, error) {
+	ctx = withOperation(ctx, "Letters.MarkAsDrafted")
+
 	u := fmt.Sprintf("api/v1/letters/%s/mark_as_drafted", letter)
 	req, err := s.client.NewRequest("POST", u, request)
 	if err != nil {
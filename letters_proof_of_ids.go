@@ -1,13 +1,59 @@
 package gocancel
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
 )
 
+// MaxProofOfIDSize is the maximum size in bytes of an uploaded proof of ID.
+const MaxProofOfIDSize = 10 << 20
+
+// ProofOfIDContentTypes are the media types accepted for proofs of ID.
+var ProofOfIDContentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+var (
+	// ErrProofOfIDTooLarge is returned when uploading a proof of ID larger
+	// than MaxProofOfIDSize.
+	ErrProofOfIDTooLarge = errors.New("proof of ID exceeds the maximum size")
+
+	// ErrProofOfIDContentType is returned when uploading a proof of ID that
+	// is not one of ProofOfIDContentTypes, or whose content does not match
+	// its content type.
+	ErrProofOfIDContentType = errors.New("unsupported proof of ID content type")
+)
+
+// ProofOfID represents an uploaded proof of ID.
+type ProofOfID struct {
+	ID          *string    `json:"id,omitempty"`
+	Filename    *string    `json:"filename,omitempty"`
+	ContentType *string    `json:"content_type,omitempty"`
+	Size        *int64     `json:"size,omitempty"`
+	CreatedAt   *Timestamp `json:"created_at,omitempty"`
+}
+
+func (p ProofOfID) String() string {
+	return Stringify(p)
+}
+
+type proofOfIDRoot struct {
+	ProofOfID *ProofOfID `json:"proof_of_id"`
+}
+
 // DownloadProofOfID downloads a proof of ID for a letter
 func (s *LettersService) DownloadProofOfID(ctx context.Context, letter string, proof_of_id string) (io.ReadCloser, *Response, error) {
+	ctx = withOperation(ctx, "Letters.DownloadProofOfID")
+
 	u := fmt.Sprintf("api/v1/letters/%v/proof_of_ids/%v", letter, proof_of_id)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -15,15 +61,152 @@ func (s *LettersService) DownloadProofOfID(ctx context.Context, letter string, p
 	}
 	req.Header.Set("Accept", mediaTypeLetterProofOfID)
 
-	resp, err := s.client.client.Do(req)
+	resp, err := s.client.BareDo(ctx, req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return resp.Body, resp, nil
+}
+
+// DownloadProofOfIDTo downloads a proof of ID for a letter into w, starting at
+// offset 0. Interrupted transfers are resumed like DownloadDocumentTo does.
+func (s *LettersService) DownloadProofOfIDTo(ctx context.Context, letter string, proof_of_id string, w io.WriterAt) (*Download, *Response, error) {
+	ctx = withOperation(ctx, "Letters.DownloadProofOfIDTo")
+
+	u := fmt.Sprintf("api/v1/letters/%v/proof_of_ids/%v", letter, proof_of_id)
+	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
+	req.Header.Set("Accept", mediaTypeLetterProofOfID)
+
+	return s.client.downloadTo(ctx, req, w)
+}
+
+// UploadProofOfID uploads an identity document, such as a scan of a passport,
+// read from r. The ID of the returned proof of ID can be passed in
+// LetterRequest.ProofOfIDs.
+//
+// The document is streamed as a multipart upload without buffering it in
+// memory. If contentType is empty, it is derived from the extension of
+// filename. The content type must be one of ProofOfIDContentTypes and match
+// the content of the document, and the document may not exceed
+// MaxProofOfIDSize bytes. Since the document is read only once, a failed
+// upload is not retried.
+//
+// If the client has an ImageSanitizer, see SetImageSanitizer, JPEG and PNG
+// images are sanitized before they are uploaded. This requires the image to be
+// read into memory.
+func (s *LettersService) UploadProofOfID(ctx context.Context, r io.Reader, filename, contentType string) (*ProofOfID, *Response, error) {
+	ctx = withOperation(ctx, "Letters.UploadProofOfID")
 
-	if err := CheckResponse(resp); err != nil {
-		resp.Body.Close()
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mt
+	}
+	if !isProofOfIDContentType(contentType) {
+		return nil, nil, fmt.Errorf("%w: %q", ErrProofOfIDContentType, contentType)
+	}
+
+	if size, ok := readerSize(r); ok && size > MaxProofOfIDSize {
+		return nil, nil, ErrProofOfIDTooLarge
+	}
+
+	// Sniff the content type from the start of the document, without
+	// consuming it.
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
 		return nil, nil, err
 	}
+	if sniffed := http.DetectContentType(head); !strings.HasPrefix(sniffed, contentType) {
+		return nil, nil, fmt.Errorf("%w: content is %q, not %q", ErrProofOfIDContentType, sniffed, contentType)
+	}
+
+	if s.client.imageSanitizer != nil && (contentType == "image/jpeg" || contentType == "image/png") {
+		data, err := s.client.imageSanitizer.Sanitize(br, contentType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sanitizing proof of ID: %w", err)
+		}
+		br = bufio.NewReader(bytes.NewReader(data))
+	}
+
+	req, err := s.client.NewRequest("POST", "api/v1/proof_of_ids", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeProofOfIDPart(mw, br, filename, contentType))
+	}()
+
+	req.Body = pr
+	req.GetBody = nil
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	root := new(proofOfIDRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.ProofOfID, resp, nil
+}
+
+// writeProofOfIDPart writes the multipart body of a proof of ID upload,
+// failing once more than MaxProofOfIDSize bytes have been read from r.
+func writeProofOfIDPart(mw *multipart.Writer, r io.Reader, filename, contentType string) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	h.Set("Content-Type", contentType)
+
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(part, io.LimitReader(r, MaxProofOfIDSize+1))
+	if err != nil {
+		return err
+	}
+	if n > MaxProofOfIDSize {
+		return ErrProofOfIDTooLarge
+	}
+
+	return mw.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func isProofOfIDContentType(contentType string) bool {
+	for _, t := range ProofOfIDContentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// readerSize returns the size of the content of r, if r can tell.
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }: // *bytes.Buffer, *bytes.Reader, *strings.Reader
+		return int64(r.Len()), true
+	case interface{ Stat() (fs.FileInfo, error) }: // *os.File
+		fi, err := r.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return 0, false
+		}
+		return fi.Size(), true
+	}
 
-	return resp.Body, newResponse(resp), nil
+	return 0, false
 }
@@ -3,10 +3,16 @@ package gocancel
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestLettersService_DownloadProofOfID(t *testing.T) {
@@ -43,3 +49,149 @@ func TestLettersService_DownloadProofOfID(t *testing.T) {
 		return err
 	})
 }
+
+func TestLettersService_DownloadProofOfIDTo(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/a/proof_of_ids/b", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testHeader(t, r, "Accept", mediaTypeLetterProofOfID)
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Disposition", "attachment; filename=proof.png")
+		fmt.Fprint(w, "Hello World")
+	})
+
+	ctx := context.Background()
+	w := new(writerAt)
+	download, _, err := client.Letters.DownloadProofOfIDTo(ctx, "a", "b", w)
+	if err != nil {
+		t.Fatalf("Letters.DownloadProofOfIDTo returned error: %v", err)
+	}
+
+	want := []byte("Hello World")
+	if !bytes.Equal(w.buf, want) {
+		t.Errorf("Letters.DownloadProofOfIDTo wrote %+v, want %+v", w.buf, want)
+	}
+	if download.Filename != "proof.png" || download.ContentType != "image/png" || download.ContentLength != int64(len(want)) {
+		t.Errorf("Letters.DownloadProofOfIDTo returned %+v", download)
+	}
+
+	const methodName = "DownloadProofOfIDTo"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Letters.DownloadProofOfIDTo(ctx, "\n", "\n", w)
+		return err
+	})
+}
+
+// testPNG is the start of a PNG image, enough for content sniffing.
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestLettersService_UploadProofOfID(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/proof_of_ids", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		if r.ContentLength != -1 {
+			t.Errorf("Request has Content-Length %d, want a streamed body", r.ContentLength)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Reading uploaded file returned error: %v", err)
+		}
+		defer file.Close()
+
+		if header.Filename != `pass"port.png` {
+			t.Errorf("Uploaded filename = %q, want %q", header.Filename, `pass"port.png`)
+		}
+		if got := header.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("Uploaded Content-Type = %q, want %q", got, "image/png")
+		}
+		if content, _ := ioutil.ReadAll(file); !bytes.Equal(content, testPNG) {
+			t.Errorf("Uploaded content = %q, want %q", content, testPNG)
+		}
+
+		fmt.Fprint(w, `{"proof_of_id":{"id":"p"}}`)
+	})
+
+	ctx := context.Background()
+	proofOfID, _, err := client.Letters.UploadProofOfID(ctx, iotest.OneByteReader(bytes.NewReader(testPNG)), `pass"port.png`, "")
+	if err != nil {
+		t.Fatalf("Letters.UploadProofOfID returned error: %v", err)
+	}
+
+	want := &ProofOfID{ID: String("p")}
+	if !cmp.Equal(proofOfID, want) {
+		t.Errorf("Letters.UploadProofOfID returned %+v, want %+v", proofOfID, want)
+	}
+
+	const methodName = "UploadProofOfID"
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		got, resp, err := client.Letters.UploadProofOfID(ctx, bytes.NewReader(testPNG), "passport.png", "image/png")
+		if got != nil {
+			t.Errorf("testNewRequestAndDoFailure %v = %#v, want nil", methodName, got)
+		}
+		return resp, err
+	})
+}
+
+func TestLettersService_UploadProofOfID_invalid(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/proof_of_ids", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		fmt.Fprint(w, `{"proof_of_id":{"id":"p"}}`)
+	})
+
+	tooLarge := append(append([]byte(nil), testPNG...), make([]byte, MaxProofOfIDSize)...)
+
+	tests := []struct {
+		name        string
+		r           io.Reader
+		filename    string
+		contentType string
+		want        error
+	}{
+		{"unsupported type", strings.NewReader("Hello World"), "passport.txt", "", ErrProofOfIDContentType},
+		{"unknown extension", bytes.NewReader(testPNG), "passport", "", ErrProofOfIDContentType},
+		{"content mismatch", strings.NewReader("%PDF-1.4"), "passport.png", "image/png", ErrProofOfIDContentType},
+		{"known size too large", bytes.NewReader(tooLarge), "passport.png", "", ErrProofOfIDTooLarge},
+		{"streamed too large", ioutil.NopCloser(bytes.NewReader(tooLarge)), "passport.png", "", ErrProofOfIDTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := client.Letters.UploadProofOfID(context.Background(), tt.r, tt.filename, tt.contentType)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Letters.UploadProofOfID returned %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLettersService_UploadProofOfID_notRetried(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	client.retryPolicy = &RetryPolicy{MaxAttempts: 3}
+
+	requests := 0
+	mux.HandleFunc("/api/v1/proof_of_ids", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, resp, err := client.Letters.UploadProofOfID(context.Background(), bytes.NewReader(testPNG), "passport.png", "")
+
+	var sErr *ServerError
+	if !errors.As(err, &sErr) {
+		t.Errorf("Letters.UploadProofOfID returned %v, want a *ServerError", err)
+	}
+	if resp == nil || resp.Attempts != 1 || requests != 1 {
+		t.Errorf("Letters.UploadProofOfID made %d requests, want 1", requests)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -27,12 +28,12 @@ func TestLetter_marshal(t *testing.T) {
 		ProviderID:            String("f172758f-7718-41f4-95d6-d3fd931e0326"),
 		ProviderConfiguration: &ProviderConfiguration{"foo": "bar"},
 		Locale:                String("nl-NL"),
-		State:                 String("generating"),
+		State:                 LetterStateGenerating.Ptr(),
 		ProofOfIDs:            []*string{String("1d7e5cf6-a871-48cd-b98a-1ecc6acbda96"), String("0971e527-ea0d-4ba2-a87b-0e8e8d4f83a2")},
 		Email:                 String("cancellations@foo.com"),
 		Fax:                   String("71-336-4530"),
 		Parameters:            &LetterParameters{"foo": "bar"},
-		SignatureType:         String("text"),
+		SignatureType:         SignatureTypeText.Ptr(),
 		SignatureData:         String("John Doe"),
 		SandboxMode:           Bool(false),
 		SandboxEmail:          String("sandbox@example.com"),
@@ -165,6 +166,77 @@ func TestLettersService_List(t *testing.T) {
 	})
 }
 
+func TestLettersService_List_filters(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, url.Values{
+			"cursor":             {"abc"},
+			"limit":              {"50"},
+			"states[]":           {"sent", "failed"},
+			"organization_ids[]": {"o"},
+			"product_ids[]":      {"p"},
+			"provider_ids[]":     {"q"},
+			"metadata[foo]":      {"bar"},
+			"created_at[gte]":    {"2021-05-01T00:00:00Z"},
+			"created_at[lte]":    {"2021-06-01T00:00:00Z"},
+			"updated_at[gte]":    {"2021-05-15T00:00:00Z"},
+			"sandbox_mode":       {"false"},
+		})
+
+		fmt.Fprint(w, `{"letters": [{"id":"b"}]}`)
+	})
+
+	ctx := context.Background()
+	opts := &LettersListOptions{
+		Cursor:          "abc",
+		Limit:           50,
+		States:          []LetterState{LetterStateSent, LetterStateFailed},
+		OrganizationIDs: []string{"o"},
+		ProductIDs:      []string{"p"},
+		ProviderIDs:     []string{"q"},
+		Metadata:        map[string]string{"foo": "bar"},
+		CreatedAt: LettersTimeRangeOptions{
+			After:  time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC),
+			Before: time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC),
+		},
+		UpdatedAt:   LettersTimeRangeOptions{After: time.Date(2021, time.May, 15, 0, 0, 0, 0, time.UTC)},
+		SandboxMode: Bool(false),
+	}
+	if _, _, err := client.Letters.List(ctx, opts); err != nil {
+		t.Errorf("Letters.List returned error: %v", err)
+	}
+}
+
+func TestLettersService_ListAll(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("cursor") == "" {
+			testFormValues(t, r, url.Values{"states[]": {"sent"}})
+			fmt.Fprint(w, `{"letters": [{"id":"a"}], "metadata": {"next_cursor": "def"}}`)
+			return
+		}
+		testFormValues(t, r, url.Values{"cursor": {"def"}, "states[]": {"sent"}})
+		fmt.Fprint(w, `{"letters": [{"id":"b"}], "metadata": {"previous_cursor": "abc"}}`)
+	})
+
+	ctx := context.Background()
+	got, err := client.Letters.ListAll(ctx, &LettersListOptions{States: []LetterState{LetterStateSent}}).Collect()
+	if err != nil {
+		t.Fatalf("Letters.ListAll returned error: %v", err)
+	}
+
+	want := []*Letter{{ID: String("a")}, {ID: String("b")}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Letters.ListAll returned %+v, want %+v", got, want)
+	}
+}
+
 func TestLettersService_Create(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -205,6 +277,65 @@ func TestLettersService_Create(t *testing.T) {
 	})
 }
 
+func TestLettersService_Create_invalidSignatureType(t *testing.T) {
+	client, _, _, teardown := setup()
+	defer teardown()
+
+	input := &LetterRequest{OrganizationID: "foo", SignatureType: "txet"}
+	_, _, err := client.Letters.Create(context.Background(), input)
+
+	var errs FieldErrors
+	if !errors.As(err, &errs) || len(errs.Field("signature_type")) != 1 {
+		t.Errorf("Letters.Create returned %v, want a signature_type field error", err)
+	}
+}
+
+func TestLetterState(t *testing.T) {
+	tests := []struct {
+		state             LetterState
+		known, isTerminal bool
+	}{
+		{LetterStateCreated, true, false},
+		{LetterStateGenerating, true, false},
+		{LetterStateDrafted, true, false},
+		{LetterStateSent, true, true},
+		{LetterStateFailed, true, true},
+		{"archived", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.IsKnown(); got != tt.known {
+			t.Errorf("LetterState(%q).IsKnown() = %t, want %t", tt.state, got, tt.known)
+		}
+		if got := tt.state.IsTerminal(); got != tt.isTerminal {
+			t.Errorf("LetterState(%q).IsTerminal() = %t, want %t", tt.state, got, tt.isTerminal)
+		}
+	}
+}
+
+func TestLetter_unknownEnums(t *testing.T) {
+	const data = `{"state":"archived","signature_type":"stamp"}`
+
+	letter := new(Letter)
+	if err := json.Unmarshal([]byte(data), letter); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if *letter.State != "archived" || letter.State.IsKnown() {
+		t.Errorf("Letter.State = %q, want unknown state %q", *letter.State, "archived")
+	}
+	if *letter.SignatureType != "stamp" || letter.SignatureType.IsKnown() {
+		t.Errorf("Letter.SignatureType = %q, want unknown type %q", *letter.SignatureType, "stamp")
+	}
+
+	got, err := json.Marshal(letter)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if string(got) != data {
+		t.Errorf("Marshal returned %s, want %s", got, data)
+	}
+}
+
 func TestLettersService_Get(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -345,6 +476,35 @@ func TestLettersService_DownloadDocument(t *testing.T) {
 	})
 }
 
+func TestLettersService_DownloadDocument_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"not_found","message":"Letter not found"}}`)
+	})
+
+	ctx := context.Background()
+	reader, resp, err := client.Letters.DownloadDocument(ctx, "b")
+
+	var nfErr *NotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Errorf("Letters.DownloadDocument returned %v, want a *NotFoundError", err)
+	}
+	if reader != nil {
+		t.Errorf("Letters.DownloadDocument returned a reader on error")
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Letters.DownloadDocument returned response %v, want a 404 response", resp)
+	}
+
+	//nolint:staticcheck //lint:ignore SA1012 we explicitly pass nil to test error
+	if _, _, err := client.Letters.DownloadDocument(nil, "b"); err != errNonNilContext {
+		t.Errorf("Letters.DownloadDocument returned %v with a nil context, want %v", err, errNonNilContext)
+	}
+}
+
 func TestLettersService_MarkAsDrafted(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
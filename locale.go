@@ -0,0 +1,253 @@
+package gocancel
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var localeTagRE = regexp.MustCompile(`^[A-Za-z]{1,8}(-[A-Za-z0-9]{1,8})*$`)
+
+type withoutPreferredLocalesContextKey struct{}
+
+// SetPreferredLocales is a client option for filtering the results of
+// CategoriesService.List, OrganizationsService.List and
+// OrganizationsService.ListProducts, and their ListAll variants, by the given
+// BCP 47 language tags when the options passed to them have no Locales. Use
+// WithoutPreferredLocales to list the resources in all locales.
+func SetPreferredLocales(tags ...string) ClientOpt {
+	return func(c *Client) error {
+		locales := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			tag = normalizeLocale(tag)
+			if !localeTagRE.MatchString(tag) {
+				return fmt.Errorf("invalid language tag %q", tag)
+			}
+			locales = append(locales, tag)
+		}
+
+		c.preferredLocales = locales
+		return nil
+	}
+}
+
+// WithoutPreferredLocales returns a copy of ctx for which List calls are not
+// filtered by the locales set with SetPreferredLocales.
+func WithoutPreferredLocales(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutPreferredLocalesContextKey{}, true)
+}
+
+// localesFilter returns the Locales filter of a List call made with ctx: the
+// given locales if any, and otherwise the preferred locales of the client,
+// unless disabled for ctx.
+func (c *Client) localesFilter(ctx context.Context, locales []string) []string {
+	if len(locales) > 0 || ctx == nil {
+		return locales
+	}
+	if without, _ := ctx.Value(withoutPreferredLocalesContextKey{}).(bool); without {
+		return locales
+	}
+
+	return c.preferredLocales
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header,
+// ordered by their quality value, e.g. "nl-BE, nl;q=0.9, en;q=0.8". The
+// wildcard, tags with a quality of zero and malformed tags are omitted.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params := part, ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			tag, params = part[:i], part[i+1:]
+		}
+		tag = normalizeLocale(tag)
+		if tag == "*" || !localeTagRE.MatchString(tag) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag, q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+// LocaleFor returns the locale of the organization best matching the given
+// BCP 47 language tags, in order of preference. See matchLocale for the
+// fallback rules. If none of the tags match, the first locale is returned as
+// the default. LocaleFor returns nil if the organization has no locales.
+func (o *Organization) LocaleFor(tags ...string) *OrganizationLocale {
+	if i := o.localeIndex(tags); i >= 0 {
+		return o.Locales[i]
+	}
+	if len(o.Locales) > 0 {
+		return o.Locales[0]
+	}
+	return nil
+}
+
+// localeIndex returns the index of the locale of the organization best
+// matching tags, or -1 if none match.
+func (o *Organization) localeIndex(tags []string) int {
+	return matchLocale(tags, len(o.Locales), func(i int) *string {
+		if o.Locales[i] == nil {
+			return nil
+		}
+		return o.Locales[i].Locale
+	})
+}
+
+// LocaleFor returns the locale of the product best matching the given BCP 47
+// language tags, like Organization.LocaleFor.
+func (p *Product) LocaleFor(tags ...string) *ProductLocale {
+	if i := p.localeIndex(tags); i >= 0 {
+		return p.Locales[i]
+	}
+	if len(p.Locales) > 0 {
+		return p.Locales[0]
+	}
+	return nil
+}
+
+// localeIndex returns the index of the locale of the product best matching
+// tags, or -1 if none match.
+func (p *Product) localeIndex(tags []string) int {
+	return matchLocale(tags, len(p.Locales), func(i int) *string {
+		if p.Locales[i] == nil {
+			return nil
+		}
+		return p.Locales[i].Locale
+	})
+}
+
+// LocaleFor returns the locale of the category best matching the given BCP 47
+// language tags, like Organization.LocaleFor.
+func (c *Category) LocaleFor(tags ...string) *CategoryLocale {
+	if i := c.localeIndex(tags); i >= 0 {
+		return c.Locales[i]
+	}
+	if len(c.Locales) > 0 {
+		return c.Locales[0]
+	}
+	return nil
+}
+
+// localeIndex returns the index of the locale of the category best matching
+// tags, or -1 if none match.
+func (c *Category) localeIndex(tags []string) int {
+	return matchLocale(tags, len(c.Locales), func(i int) *string {
+		if c.Locales[i] == nil {
+			return nil
+		}
+		return c.Locales[i].Locale
+	})
+}
+
+// LocaleFor returns the locale of the provider best matching the given BCP 47
+// language tags, like Organization.LocaleFor.
+func (p *Provider) LocaleFor(tags ...string) *ProviderLocale {
+	i := matchLocale(tags, len(p.Locales), func(i int) *string {
+		if p.Locales[i] == nil {
+			return nil
+		}
+		return p.Locales[i].Locale
+	})
+	if i >= 0 {
+		return p.Locales[i]
+	}
+	if len(p.Locales) > 0 {
+		return p.Locales[0]
+	}
+	return nil
+}
+
+// matchLocale returns the index of the one of n locales best matching tags,
+// or -1 if none match. locale returns the tag of the i-th locale.
+//
+// Each tag is tried in order. A tag matches a locale with the same tag, and
+// otherwise a locale with one of its prefixes, so "nl-BE" falls back to "nl".
+// Failing that, it matches the first locale of the same language, so "nl-BE"
+// also matches "nl-NL". Tags are compared case-insensitively, and underscores
+// are treated as hyphens.
+func matchLocale(tags []string, n int, locale func(i int) *string) int {
+	locales := make([]string, n)
+	for i := range locales {
+		if tag := locale(i); tag != nil {
+			locales[i] = strings.ToLower(normalizeLocale(*tag))
+		}
+	}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(normalizeLocale(tag))
+		if tag == "" {
+			continue
+		}
+
+		for prefix := tag; prefix != ""; prefix = parentLocale(prefix) {
+			for i, l := range locales {
+				if l == prefix {
+					return i
+				}
+			}
+		}
+
+		language := tag
+		if i := strings.IndexByte(tag, '-'); i >= 0 {
+			language = tag[:i]
+		}
+		for i, l := range locales {
+			if l == language || strings.HasPrefix(l, language+"-") {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parentLocale returns tag without its last subtag, or an empty string if tag
+// has a single subtag.
+func parentLocale(tag string) string {
+	if i := strings.LastIndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return ""
+}
+
+// normalizeLocale trims a language tag and replaces underscores, as used by
+// POSIX locales, with hyphens.
+func normalizeLocale(tag string) string {
+	return strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+}
@@ -0,0 +1,125 @@
+package gocancel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOrganization_LocaleFor(t *testing.T) {
+	org := &Organization{Locales: []*OrganizationLocale{
+		{Locale: String("en-US")},
+		{Locale: String("nl")},
+		{Locale: String("fr-FR")},
+		{Locale: String("de_DE")},
+	}}
+
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"nl"}, "nl"},
+		{[]string{"nl-BE"}, "nl"},
+		{[]string{"NL_be"}, "nl"},
+		{[]string{"fr-BE"}, "fr-FR"},
+		{[]string{"de-DE"}, "de_DE"},
+		{[]string{"es", "fr"}, "fr-FR"},
+		{[]string{"en-GB", "nl"}, "en-US"},
+		{[]string{"es"}, "en-US"},
+		{nil, "en-US"},
+	}
+
+	for _, tt := range tests {
+		got := org.LocaleFor(tt.tags...)
+		if got == nil || *got.Locale != tt.want {
+			t.Errorf("Organization.LocaleFor(%q) returned %v, want %q", tt.tags, got, tt.want)
+		}
+	}
+
+	if got := new(Organization).LocaleFor("nl"); got != nil {
+		t.Errorf("Organization.LocaleFor returned %v without locales, want nil", got)
+	}
+}
+
+func TestLocaleFor(t *testing.T) {
+	product := &Product{Locales: []*ProductLocale{{Locale: String("en")}, {Locale: String("nl-NL")}}}
+	if got := product.LocaleFor("nl-BE"); got == nil || *got.Locale != "nl-NL" {
+		t.Errorf("Product.LocaleFor returned %v, want %q", got, "nl-NL")
+	}
+
+	category := &Category{Locales: []*CategoryLocale{nil, {Locale: String("nl")}}}
+	if got := category.LocaleFor("nl-BE"); got == nil || *got.Locale != "nl" {
+		t.Errorf("Category.LocaleFor returned %v, want %q", got, "nl")
+	}
+
+	provider := &Provider{Locales: []*ProviderLocale{{Locale: String("en")}, {Locale: String("nl")}}}
+	if got := provider.LocaleFor("fr"); got == nil || *got.Locale != "en" {
+		t.Errorf("Provider.LocaleFor returned %v, want %q", got, "en")
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"nl-BE", []string{"nl-BE"}},
+		{"nl-BE, nl;q=0.9, en;q=0.8", []string{"nl-BE", "nl", "en"}},
+		{"en;q=0.5, fr, de;q=0.7", []string{"fr", "de", "en"}},
+		{"*, nl;q=0, en_US;q=0.3, b@d", []string{"en-US"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !cmp.Equal(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) returned %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSetPreferredLocales(t *testing.T) {
+	if _, err := New(nil, SetPreferredLocales("nl-BE", "b@d")); err == nil {
+		t.Error("SetPreferredLocales accepted an invalid language tag")
+	}
+
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetPreferredLocales("nl_BE", "nl")(client); err != nil {
+		t.Fatalf("SetPreferredLocales returned error: %v", err)
+	}
+
+	mux.HandleFunc("/api/v1/organizations", func(w http.ResponseWriter, r *http.Request) {
+		want := []string{"nl-BE", "nl"}
+		switch r.URL.Query().Get("limit") {
+		case "1":
+			want = []string{"en"}
+		case "2":
+			want = nil
+		}
+		if got := r.URL.Query()["locales[]"]; !cmp.Equal(got, want) {
+			t.Errorf("Request locales = %q, want %q", got, want)
+		}
+		w.Write([]byte(`{"organizations":[]}`))
+	})
+
+	ctx := context.Background()
+	if _, _, err := client.Organizations.List(ctx, nil); err != nil {
+		t.Fatalf("Organizations.List returned error: %v", err)
+	}
+
+	opts := &OrganizationsListOptions{Limit: 1, Locales: []string{"en"}}
+	if _, _, err := client.Organizations.List(ctx, opts); err != nil {
+		t.Fatalf("Organizations.List returned error: %v", err)
+	}
+
+	opts = &OrganizationsListOptions{Limit: 2}
+	if _, _, err := client.Organizations.List(WithoutPreferredLocales(ctx), opts); err != nil {
+		t.Fatalf("Organizations.List returned error: %v", err)
+	}
+	if opts.Locales != nil {
+		t.Errorf("Organizations.List modified the options, Locales = %q", opts.Locales)
+	}
+}
@@ -0,0 +1,197 @@
+package gocancel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gocancel/gocancel-go/internal/redact"
+)
+
+const (
+	headerRequestID = "X-Request-Id"
+
+	// maxLoggedBody is the maximum size of a request or response body that
+	// is logged. Larger bodies are omitted from the log.
+	maxLoggedBody = 64 << 10
+
+	// redacted replaces the values masked by a Redactor.
+	redacted = "[REDACTED]"
+)
+
+// DefaultRedactedFields are the JSON fields holding personal data, such as
+// the email address, address and signature of a letter and its parameters,
+// whose values are masked in logged request and response bodies. The recorder
+// package scrubs the same fields from its cassettes.
+var DefaultRedactedFields = []string{
+	"email",
+	"sandbox_email",
+	"phone",
+	"fax",
+	"address",
+	"signature_data",
+	"parameters",
+}
+
+// LogEntry describes a single attempt of an API request.
+type LogEntry struct {
+	// Operation is the service method that made the request, e.g.
+	// "Letters.Create".
+	Operation string
+
+	Method string
+	Path   string
+
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+
+	// StatusCode is zero if no response was received.
+	StatusCode int
+
+	// RequestID is the ID assigned to the request by the API, if any.
+	RequestID string
+
+	Duration time.Duration
+
+	// RequestBody and ResponseBody hold the redacted bodies. They are nil
+	// for empty, binary and oversized bodies.
+	RequestBody  []byte
+	ResponseBody []byte
+
+	// Err is the error sending the request, if any.
+	Err error
+}
+
+// Logger logs the API requests made by the client, see SetLogger.
+type Logger interface {
+	Log(ctx context.Context, e *LogEntry)
+}
+
+// LoggerFunc is an adapter to allow the use of ordinary functions as Logger.
+type LoggerFunc func(ctx context.Context, e *LogEntry)
+
+// Log calls f(ctx, e).
+func (f LoggerFunc) Log(ctx context.Context, e *LogEntry) {
+	f(ctx, e)
+}
+
+// Redactor masks personal data in logged request and response bodies. The
+// values of DefaultRedactedFields and of Fields are replaced, wherever they
+// occur in a JSON body, as are matches of Patterns in the remaining string
+// values. The zero value masks DefaultRedactedFields.
+type Redactor struct {
+	// Fields are JSON fields to mask in addition to DefaultRedactedFields.
+	// Fields are matched case-insensitively.
+	Fields []string
+
+	// Patterns are masked in all string values, e.g. IBANs or phone
+	// numbers in free-form text.
+	Patterns []*regexp.Regexp
+}
+
+// SetLogger is a client option for logging every attempt of every API
+// request, including its redacted request and response bodies. Bodies are
+// redacted with the zero Redactor, unless SetLogRedactor is used.
+func SetLogger(l Logger) ClientOpt {
+	return func(c *Client) error {
+		c.logger = l
+		return nil
+	}
+}
+
+// SetLogRedactor is a client option for the redactor applied to the bodies
+// logged by the Logger of the client.
+func SetLogRedactor(r Redactor) ClientOpt {
+	return func(c *Client) error {
+		c.redactor = r
+		return nil
+	}
+}
+
+// Redact returns body with personal data masked. Bodies that are not valid
+// JSON are masked entirely, since they cannot be redacted field by field.
+func (r Redactor) Redact(body []byte) []byte {
+	w := &redact.Walker{
+		Fields: redact.NewFields(append(append([]string(nil), DefaultRedactedFields...), r.Fields...)...),
+		Mask:   func(interface{}) interface{} { return redacted },
+		String: func(s string) string {
+			for _, p := range r.Patterns {
+				s = p.ReplaceAllLiteralString(s, redacted)
+			}
+			return s
+		},
+	}
+
+	masked, ok, _ := w.JSON(body)
+	if !ok {
+		return []byte(`"` + redacted + `"`)
+	}
+	return masked
+}
+
+// logAttempt logs an attempt of req, which took d and resulted in resp or
+// err. The body of resp is restored after reading it.
+func (c *Client) logAttempt(req *http.Request, resp *http.Response, err error, attempt int, d time.Duration) {
+	if c.logger == nil {
+		return
+	}
+
+	e := &LogEntry{
+		Operation: OperationFromContext(req.Context()),
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Attempt:   attempt,
+		Duration:  d,
+		Err:       err,
+	}
+
+	if req.GetBody != nil && isTextual(req.Header) {
+		if body, err := req.GetBody(); err == nil {
+			e.RequestBody = c.logBody(body)
+			body.Close()
+		}
+	}
+
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		e.RequestID = resp.Header.Get(headerRequestID)
+
+		if isTextual(resp.Header) {
+			buf, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+			resp.Body = &readCloser{io.MultiReader(bytes.NewReader(buf), resp.Body), resp.Body}
+			e.ResponseBody = c.logBody(bytes.NewReader(buf))
+		}
+	}
+
+	c.logger.Log(req.Context(), e)
+}
+
+// logBody returns the redacted body read from r, or nil if it is empty or
+// too large to log.
+func (c *Client) logBody(r io.Reader) []byte {
+	body, err := ioutil.ReadAll(io.LimitReader(r, maxLoggedBody+1))
+	if err != nil || len(bytes.TrimSpace(body)) == 0 || len(body) > maxLoggedBody {
+		return nil
+	}
+
+	return c.redactor.Redact(body)
+}
+
+// isTextual reports whether h describes a JSON or text body, as opposed to a
+// downloaded document.
+func isTextual(h http.Header) bool {
+	mt, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json") || strings.HasPrefix(mt, "text/"))
+}
+
+// readCloser reads from one reader and closes another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,161 @@
+package gocancel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// logRecorder is a Logger recording the entries it logs.
+type logRecorder struct {
+	entries []*LogEntry
+}
+
+func (l *logRecorder) Log(ctx context.Context, e *LogEntry) {
+	l.entries = append(l.entries, e)
+}
+
+func TestSetLogger(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	logs := new(logRecorder)
+	if err := SetLogger(logs)(client); err != nil {
+		t.Fatalf("SetLogger returned error: %v", err)
+	}
+
+	mux.HandleFunc("/api/v1/letters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"letter":{"id":"b","email":"jane@example.com","address":{"locality":"Utrecht"}}}`)
+	})
+
+	input := &LetterRequest{
+		OrganizationID: "a",
+		Parameters:     LetterParameters{"name": "Jane Doe"},
+		SignatureData:  "Jane Doe",
+	}
+	letter, _, err := client.Letters.Create(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Letters.Create returned error: %v", err)
+	}
+	if *letter.Email != "jane@example.com" {
+		t.Errorf("Letters.Create returned email %q, want the unredacted email", *letter.Email)
+	}
+
+	if len(logs.entries) != 1 {
+		t.Fatalf("Logger received %d entries, want 1", len(logs.entries))
+	}
+	e := logs.entries[0]
+
+	if e.Operation != "Letters.Create" || e.Method != "POST" || e.Path != "/api/v1/letters" ||
+		e.StatusCode != http.StatusCreated || e.RequestID != "req-1" || e.Attempt != 1 {
+		t.Errorf("Logger received %+v", e)
+	}
+
+	wantReq := `{"organization_id":"a","parameters":"[REDACTED]","signature_data":"[REDACTED]"}`
+	if string(e.RequestBody) != wantReq {
+		t.Errorf("Logged request body = %s, want %s", e.RequestBody, wantReq)
+	}
+	wantResp := `{"letter":{"address":"[REDACTED]","email":"[REDACTED]","id":"b"}}`
+	if string(e.ResponseBody) != wantResp {
+		t.Errorf("Logged response body = %s, want %s", e.ResponseBody, wantResp)
+	}
+}
+
+func TestSetLogger_attempts(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	logs := new(logRecorder)
+	if err := SetLogger(logs)(client); err != nil {
+		t.Fatalf("SetLogger returned error: %v", err)
+	}
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	calls := 0
+	mux.HandleFunc("/api/v1/letters/b/document", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(testDocument)
+	})
+
+	body, _, err := client.Letters.DownloadDocument(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("Letters.DownloadDocument returned error: %v", err)
+	}
+	defer body.Close()
+
+	var got []int
+	for _, e := range logs.entries {
+		got = append(got, e.StatusCode)
+		if e.Attempt != len(got) {
+			t.Errorf("Entry %d has attempt %d", len(got), e.Attempt)
+		}
+	}
+	if want := []int{http.StatusServiceUnavailable, http.StatusOK}; !cmp.Equal(got, want) {
+		t.Errorf("Logged status codes %v, want %v", got, want)
+	}
+	if string(logs.entries[0].ResponseBody) != `"[REDACTED]"` {
+		t.Errorf("Logged text body = %s, want it masked", logs.entries[0].ResponseBody)
+	}
+	if logs.entries[1].ResponseBody != nil {
+		t.Errorf("Logged document body %q, want none", logs.entries[1].ResponseBody)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Reading document returned error: %v", err)
+	}
+	if !bytes.Equal(data, testDocument) {
+		t.Error("Letters.DownloadDocument returned a different document")
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	r := Redactor{
+		Fields:   []string{"Customer_Number"},
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`NL\d{2}[A-Z]{4}\d{10}`)},
+	}
+
+	tests := []struct {
+		body, want string
+	}{
+		{
+			`{"letters":[{"email":"a@b.c","sandbox_email":null,"customer_number":12345,"note":"IBAN NL91ABNA0417164300"}]}`,
+			`{"letters":[{"customer_number":"[REDACTED]","email":"[REDACTED]","note":"IBAN [REDACTED]","sandbox_email":null}]}`,
+		},
+		{`{"count":12345678901234567890}`, `{"count":12345678901234567890}`},
+		{`not json`, `"[REDACTED]"`},
+	}
+
+	for _, tt := range tests {
+		if got := string(r.Redact([]byte(tt.body))); got != tt.want {
+			t.Errorf("Redact(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerFunc(t *testing.T) {
+	var got *LogEntry
+	l := LoggerFunc(func(ctx context.Context, e *LogEntry) { got = e })
+
+	want := &LogEntry{Method: "GET"}
+	l.Log(context.Background(), want)
+	if got != want {
+		t.Errorf("LoggerFunc received %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,24 @@
 package gocancel
 
+import (
+	"fmt"
+	"net/url"
+)
+
 // Metadata represents the cursors for list responses.
 type Metadata struct {
 	NextCursor     string `json:"next_cursor,omitempty"`
 	PreviousCursor string `json:"previous_cursor,omitempty"`
 }
+
+// MetadataFilter filters resources by their account metadata. It is encoded as
+// one query parameter per key, e.g. metadata[foo]=bar.
+type MetadataFilter map[string]string
+
+// EncodeValues implements the query.Encoder interface.
+func (m MetadataFilter) EncodeValues(key string, v *url.Values) error {
+	for k, val := range m {
+		v.Set(fmt.Sprintf("%s[%s]", key, k), val)
+	}
+	return nil
+}
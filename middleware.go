@@ -0,0 +1,58 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Handler sends an API request and returns the API response. If an API error
+// occurs, both the response and the error are returned. The body of the
+// returned response has not been read yet.
+type Handler func(req *http.Request) (*Response, error)
+
+// Middleware wraps the Handler sending API requests, to observe or alter
+// requests and responses. It may also return a response or error of its own
+// without calling next, e.g. to inject faults in tests.
+type Middleware func(next Handler) Handler
+
+type operationContextKey struct{}
+
+// WithMiddleware is a client option for wrapping every API call with the
+// given middleware. Middleware sees a call once, no matter how many attempts
+// the retry policy makes. The name of the service method that made the call,
+// such as "Letters.Create", is available from the context of the request
+// using OperationFromContext.
+//
+// The first middleware is the outermost: it sees the request first and the
+// response last. Using the option multiple times appends to the chain.
+func WithMiddleware(mw ...Middleware) ClientOpt {
+	return func(c *Client) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("middleware must be non-nil")
+			}
+		}
+
+		c.middleware = append(c.middleware, mw...)
+		return nil
+	}
+}
+
+// OperationFromContext returns the name of the service method that made an
+// API call, e.g. "Letters.Create", from the context of its request. It
+// returns an empty string for requests not made by a service method.
+func OperationFromContext(ctx context.Context) string {
+	op, _ := ctx.Value(operationContextKey{}).(string)
+	return op
+}
+
+// withOperation returns a copy of ctx carrying the name of the service method
+// making an API call. A nil ctx is returned as is, leaving BareDo to reject
+// it.
+func withOperation(ctx context.Context, op string) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, operationContextKey{}, op)
+}
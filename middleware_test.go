@@ -0,0 +1,123 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "X-Audit", "inner")
+		fmt.Fprint(w, `{"letter":{"id":"b"}}`)
+	})
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*Response, error) {
+				calls = append(calls, name+" "+OperationFromContext(req.Context()))
+				req.Header.Set("X-Audit", name)
+				resp, err := next(req)
+				calls = append(calls, fmt.Sprintf("%s %d", name, resp.StatusCode))
+				return resp, err
+			}
+		}
+	}
+
+	if err := WithMiddleware(trace("outer"), trace("inner"))(client); err != nil {
+		t.Fatalf("WithMiddleware returned error: %v", err)
+	}
+
+	if _, _, err := client.Letters.Get(context.Background(), "b"); err != nil {
+		t.Fatalf("Letters.Get returned error: %v", err)
+	}
+
+	want := []string{"outer Letters.Get", "inner Letters.Get", "inner 200", "outer 200"}
+	if !cmp.Equal(calls, want) {
+		t.Errorf("Middleware calls = %q, want %q", calls, want)
+	}
+}
+
+func TestWithMiddleware_error(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/letters/b", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"not_found","message":"Letter not found"}}`)
+	})
+
+	var gotErr error
+	mw := func(next Handler) Handler {
+		return func(req *http.Request) (*Response, error) {
+			resp, err := next(req)
+			gotErr = err
+			return resp, err
+		}
+	}
+	if err := WithMiddleware(mw)(client); err != nil {
+		t.Fatalf("WithMiddleware returned error: %v", err)
+	}
+
+	_, _, err := client.Letters.Get(context.Background(), "b")
+
+	var nfErr *NotFoundError
+	if !errors.As(gotErr, &nfErr) {
+		t.Errorf("Middleware saw error %v, want a *NotFoundError", gotErr)
+	}
+	if err != gotErr {
+		t.Errorf("Letters.Get returned %v, want %v", err, gotErr)
+	}
+}
+
+func TestWithMiddleware_faultInjection(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request reached the server")
+	})
+
+	errInjected := errors.New("injected")
+	mw := func(next Handler) Handler {
+		return func(req *http.Request) (*Response, error) {
+			if OperationFromContext(req.Context()) == "Letters.Create" {
+				return nil, errInjected
+			}
+			return next(req)
+		}
+	}
+	if err := WithMiddleware(mw)(client); err != nil {
+		t.Fatalf("WithMiddleware returned error: %v", err)
+	}
+
+	_, _, err := client.Letters.Create(context.Background(), &LetterRequest{OrganizationID: "a"})
+	if !errors.Is(err, errInjected) {
+		t.Errorf("Letters.Create returned %v, want %v", err, errInjected)
+	}
+}
+
+func TestWithMiddleware_nil(t *testing.T) {
+	if _, err := New(nil, WithMiddleware(nil)); err == nil {
+		t.Error("WithMiddleware accepted nil middleware")
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	if op := OperationFromContext(context.Background()); op != "" {
+		t.Errorf("OperationFromContext returned %q, want empty", op)
+	}
+
+	//nolint:staticcheck //lint:ignore SA1012 we explicitly pass nil to test error
+	if ctx := withOperation(nil, "Letters.Get"); ctx != nil {
+		t.Errorf("withOperation returned %v for a nil context, want nil", ctx)
+	}
+}
@@ -60,8 +60,8 @@ func (o OrganizationLocale) String() string {
 type OrganizationProvider struct {
 	ID        *string          `json:"id,omitempty"`
 	Name      *string          `json:"name,omitempty"`
-	Type      *string          `json:"type,omitempty"`
-	Method    *string          `json:"method,omitempty"`
+	Type      *ProviderType    `json:"type,omitempty"`
+	Method    *ProviderMethod  `json:"method,omitempty"`
 	Metadata  *AccountMetadata `json:"metadata,omitempty"`
 	CreatedAt *Timestamp       `json:"created_at,omitempty"`
 	UpdatedAt *Timestamp       `json:"updated_at,omitempty"`
@@ -81,7 +81,7 @@ type OrganizationsListOptions struct {
 	Cursor   string                   `url:"cursor,omitempty"`
 	Limit    int                      `url:"limit,omitempty"`
 	Locales  []string                 `url:"locales[],omitempty"`
-	Metadata map[string]string        `url:"metadata,omitempty"`
+	Metadata MetadataFilter           `url:"metadata,omitempty"`
 	Slug     string                   `url:"slug,omitempty"`
 	Sort     OrganizationsSortOptions `url:"sort,omitempty"`
 	URL      string                   `url:"url,omitempty"`
@@ -98,6 +98,15 @@ type organizationsRoot struct {
 
 // List lists all organizations
 func (s *OrganizationsService) List(ctx context.Context, opts *OrganizationsListOptions) ([]*Organization, *Response, error) {
+	ctx = withOperation(ctx, "Organizations.List")
+
+	var o OrganizationsListOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Locales = s.client.localesFilter(ctx, o.Locales)
+	opts = &o
+
 	u, err := addOptions("api/v1/organizations", opts)
 	if err != nil {
 		return nil, nil, err
@@ -119,8 +128,52 @@ func (s *OrganizationsService) List(ctx context.Context, opts *OrganizationsList
 	return root.Organizations, resp, nil
 }
 
+// OrganizationsIterator iterates over organizations, following the pagination cursors
+// automatically.
+type OrganizationsIterator struct {
+	iterator
+	page []*Organization
+}
+
+// Value returns the current organization. It must only be called after a call
+// to Next returned true.
+func (it *OrganizationsIterator) Value() *Organization {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *OrganizationsIterator) Collect() ([]*Organization, error) {
+	var items []*Organization
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAll returns an iterator over all organizations, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *OrganizationsService) ListAll(ctx context.Context, opts *OrganizationsListOptions) *OrganizationsIterator {
+	var o OrganizationsListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(OrganizationsIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.List(ctx, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
 // Get fetches a organization.
 func (s *OrganizationsService) Get(ctx context.Context, organization string) (*Organization, *Response, error) {
+	ctx = withOperation(ctx, "Organizations.Get")
+
 	u := fmt.Sprintf("api/v1/organizations/%s", organization)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
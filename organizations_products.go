@@ -14,7 +14,7 @@ type OrganizationProductsListOptions struct {
 	Cursor   string                          `url:"cursor,omitempty"`
 	Limit    int                             `url:"limit,omitempty"`
 	Locales  []string                        `url:"locales[],omitempty"`
-	Metadata map[string]string               `url:"metadata,omitempty"`
+	Metadata MetadataFilter                  `url:"metadata,omitempty"`
 	Slug     string                          `url:"slug,omitempty"`
 	Sort     OrganizationProductsSortOptions `url:"sort,omitempty"`
 	URL      string                          `url:"url,omitempty"`
@@ -22,6 +22,15 @@ type OrganizationProductsListOptions struct {
 
 // List lists all products of an organization
 func (s *OrganizationsService) ListProducts(ctx context.Context, organization string, opts *OrganizationProductsListOptions) ([]*Product, *Response, error) {
+	ctx = withOperation(ctx, "Organizations.ListProducts")
+
+	var o OrganizationProductsListOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Locales = s.client.localesFilter(ctx, o.Locales)
+	opts = &o
+
 	u, err := addOptions(fmt.Sprintf("api/v1/organizations/%s/products", organization), opts)
 	if err != nil {
 		return nil, nil, err
@@ -43,8 +52,52 @@ func (s *OrganizationsService) ListProducts(ctx context.Context, organization st
 	return root.Products, resp, nil
 }
 
+// ProductsIterator iterates over products of an organization, following the pagination cursors
+// automatically.
+type ProductsIterator struct {
+	iterator
+	page []*Product
+}
+
+// Value returns the current product. It must only be called after a call
+// to Next returned true.
+func (it *ProductsIterator) Value() *Product {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *ProductsIterator) Collect() ([]*Product, error) {
+	var items []*Product
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAllProducts returns an iterator over all products of an organization, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *OrganizationsService) ListAllProducts(ctx context.Context, organization string, opts *OrganizationProductsListOptions) *ProductsIterator {
+	var o OrganizationProductsListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(ProductsIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.ListProducts(ctx, organization, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
 // Get fetches a product of an organization.
 func (s *OrganizationsService) GetProduct(ctx context.Context, organization string, product string) (*Product, *Response, error) {
+	ctx = withOperation(ctx, "Organizations.GetProduct")
+
 	u := fmt.Sprintf("api/v1/organizations/%s/products/%s", organization, product)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -16,13 +16,13 @@ func TestOrganizationsService_ListProducts(t *testing.T) {
 
 	mux.HandleFunc("/api/v1/organizations/a/products", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
-		testFormValues(t, r, url.Values{"sort[created_at]": {"asc"}, "locales[]": {"nl-NL"}})
+		testFormValues(t, r, url.Values{"sort[created_at]": {"asc"}, "locales[]": {"nl-NL"}, "metadata[foo]": {"bar"}})
 
 		fmt.Fprint(w, `{"products": [{"id":"b"}]}`)
 	})
 
 	ctx := context.Background()
-	opts := &OrganizationProductsListOptions{Sort: OrganizationProductsSortOptions{CreatedAt: "asc"}, Locales: []string{"nl-NL"}}
+	opts := &OrganizationProductsListOptions{Sort: OrganizationProductsSortOptions{CreatedAt: "asc"}, Locales: []string{"nl-NL"}, Metadata: MetadataFilter{"foo": "bar"}}
 	products, _, err := client.Organizations.ListProducts(ctx, "a", opts)
 	if err != nil {
 		t.Errorf("Organizations.ListProducts returned error: %v", err)
@@ -43,6 +43,32 @@ func TestOrganizationsService_ListProducts(t *testing.T) {
 	})
 }
 
+func TestOrganizationsService_ListAllProducts(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/organizations/a/products", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("cursor") == "" {
+			testFormValues(t, r, url.Values{"limit": {"1"}})
+			fmt.Fprint(w, `{"products": [{"id":"a"}], "metadata": {"next_cursor": "def"}}`)
+			return
+		}
+		testFormValues(t, r, url.Values{"cursor": {"def"}, "limit": {"1"}})
+		fmt.Fprint(w, `{"products": [{"id":"b"}], "metadata": {"previous_cursor": "abc"}}`)
+	})
+
+	ctx := context.Background()
+	got, err := client.Organizations.ListAllProducts(ctx, "a", &OrganizationProductsListOptions{Limit: 1}).Collect()
+	if err != nil {
+		t.Fatalf("Organizations.ListAllProducts returned error: %v", err)
+	}
+
+	if len(got) != 2 || *got[0].ID != "a" || *got[1].ID != "b" {
+		t.Errorf("Organizations.ListAllProducts returned %+v, want items a and b", got)
+	}
+}
+
 func TestOrganizationsService_GetProduct(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
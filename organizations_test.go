@@ -67,8 +67,8 @@ func TestOrganization_marshal(t *testing.T) {
 					{
 						ID:     String("f8acd284-bb6a-4933-a244-dedb9797b1d5"),
 						Name:   String("Email"),
-						Type:   String("email"),
-						Method: String("single"),
+						Type:   ProviderTypeEmail.Ptr(),
+						Method: ProviderMethodSingle.Ptr(),
 					},
 				},
 
@@ -182,13 +182,13 @@ func TestOrganizationsService_List(t *testing.T) {
 
 	mux.HandleFunc("/api/v1/organizations", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
-		testFormValues(t, r, url.Values{"sort[created_at]": {"desc"}, "locales[]": {"nl-NL"}})
+		testFormValues(t, r, url.Values{"sort[created_at]": {"desc"}, "locales[]": {"nl-NL"}, "metadata[foo]": {"bar"}})
 
 		fmt.Fprint(w, `{"organizations": [{"id":"b"}], "metadata": {"next_cursor": "def", "previous_cursor": "abc"}}`)
 	})
 
 	ctx := context.Background()
-	opts := &OrganizationsListOptions{Sort: OrganizationsSortOptions{CreatedAt: "desc"}, Locales: []string{"nl-NL"}}
+	opts := &OrganizationsListOptions{Sort: OrganizationsSortOptions{CreatedAt: "desc"}, Locales: []string{"nl-NL"}, Metadata: MetadataFilter{"foo": "bar"}}
 	organizations, resp, err := client.Organizations.List(ctx, opts)
 	if err != nil {
 		t.Errorf("Organizations.List returned error: %v", err)
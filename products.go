@@ -60,8 +60,8 @@ func (o ProductLocale) String() string {
 type ProductProvider struct {
 	ID        *string          `json:"id,omitempty"`
 	Name      *string          `json:"name,omitempty"`
-	Type      *string          `json:"type,omitempty"`
-	Method    *string          `json:"method,omitempty"`
+	Type      *ProviderType    `json:"type,omitempty"`
+	Method    *ProviderMethod  `json:"method,omitempty"`
 	Metadata  *AccountMetadata `json:"metadata,omitempty"`
 	CreatedAt *Timestamp       `json:"created_at,omitempty"`
 	UpdatedAt *Timestamp       `json:"updated_at,omitempty"`
@@ -82,6 +82,8 @@ type productsRoot struct {
 
 // Get fetches a product.
 func (s *ProductsService) Get(ctx context.Context, product string) (*Product, *Response, error) {
+	ctx = withOperation(ctx, "Products.Get")
+
 	u := fmt.Sprintf("api/v1/products/%s", product)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -66,8 +66,8 @@ func TestProduct_marshal(t *testing.T) {
 					{
 						ID:     String("f8acd284-bb6a-4933-a244-dedb9797b1d5"),
 						Name:   String("Email"),
-						Type:   String("email"),
-						Method: String("single"),
+						Type:   ProviderTypeEmail.Ptr(),
+						Method: ProviderMethodSingle.Ptr(),
 					},
 				},
 
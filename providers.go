@@ -9,11 +9,57 @@ import (
 // in the GoCancel API.
 type ProvidersService service
 
+// ProviderType represents the channel a provider delivers letters through,
+// e.g. "email". Types unknown to this package are preserved when decoding and
+// encoding providers.
+type ProviderType string
+
+// This block represents the known provider types.
+const (
+	ProviderTypeEmail ProviderType = "email"
+	ProviderTypeFax   ProviderType = "fax"
+	ProviderTypePost  ProviderType = "post"
+)
+
+// IsKnown reports whether t is one of the known provider types.
+func (t ProviderType) IsKnown() bool {
+	switch t {
+	case ProviderTypeEmail, ProviderTypeFax, ProviderTypePost:
+		return true
+	}
+	return false
+}
+
+// Ptr returns a pointer to t, for use in struct literals.
+func (t ProviderType) Ptr() *ProviderType { return &t }
+
+// ProviderMethod represents how a provider delivers letters, e.g. "single".
+// Methods unknown to this package are preserved when decoding and encoding
+// providers.
+type ProviderMethod string
+
+// This block represents the known provider methods.
+const (
+	// ProviderMethodSingle delivers each letter separately.
+	ProviderMethodSingle ProviderMethod = "single"
+
+	// ProviderMethodBulk delivers letters in batches.
+	ProviderMethodBulk ProviderMethod = "bulk"
+)
+
+// IsKnown reports whether m is one of the known provider methods.
+func (m ProviderMethod) IsKnown() bool {
+	return m == ProviderMethodSingle || m == ProviderMethodBulk
+}
+
+// Ptr returns a pointer to m, for use in struct literals.
+func (m ProviderMethod) Ptr() *ProviderMethod { return &m }
+
 // Provider represents a GoCancel provider.
 type Provider struct {
 	ID             *string                `json:"id,omitempty"`
-	ProviderType   *string                `json:"provider_type,omitempty"`
-	ProviderMethod *string                `json:"provider_method,omitempty"`
+	ProviderType   *ProviderType          `json:"provider_type,omitempty"`
+	ProviderMethod *ProviderMethod        `json:"provider_method,omitempty"`
 	Configuration  *ProviderConfiguration `json:"configuration,omitempty"`
 	Locales        []*ProviderLocale      `json:"locales,omitempty"`
 	CreatedAt      *Timestamp             `json:"created_at,omitempty"`
@@ -56,6 +102,8 @@ type providersRoot struct {
 
 // List lists all providers
 func (s *ProvidersService) List(ctx context.Context, opts *ProvidersListOptions) ([]*Provider, *Response, error) {
+	ctx = withOperation(ctx, "Providers.List")
+
 	u, err := addOptions("api/v1/providers", opts)
 	if err != nil {
 		return nil, nil, err
@@ -77,8 +125,52 @@ func (s *ProvidersService) List(ctx context.Context, opts *ProvidersListOptions)
 	return root.Providers, resp, nil
 }
 
+// ProvidersIterator iterates over providers, following the pagination cursors
+// automatically.
+type ProvidersIterator struct {
+	iterator
+	page []*Provider
+}
+
+// Value returns the current provider. It must only be called after a call
+// to Next returned true.
+func (it *ProvidersIterator) Value() *Provider {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *ProvidersIterator) Collect() ([]*Provider, error) {
+	var items []*Provider
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAll returns an iterator over all providers, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *ProvidersService) ListAll(ctx context.Context, opts *ProvidersListOptions) *ProvidersIterator {
+	var o ProvidersListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(ProvidersIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.List(ctx, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
 // Get fetches a provider.
 func (s *ProvidersService) Get(ctx context.Context, provider string) (*Provider, *Response, error) {
+	ctx = withOperation(ctx, "Providers.Get")
+
 	u := fmt.Sprintf("api/v1/providers/%s", provider)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
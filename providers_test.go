@@ -16,8 +16,8 @@ func TestProvider_marshal(t *testing.T) {
 
 	o := &Provider{
 		ID:             String("2637d3dd-b556-409f-8f36-cd2f6d08ab77"),
-		ProviderType:   String("email"),
-		ProviderMethod: String("single"),
+		ProviderType:   ProviderTypeEmail.Ptr(),
+		ProviderMethod: ProviderMethodSingle.Ptr(),
 		Configuration:  &ProviderConfiguration{"foo": "bar"},
 		Locales: []*ProviderLocale{
 			{
@@ -95,6 +95,32 @@ func TestProvidersService_List(t *testing.T) {
 	})
 }
 
+func TestProvidersService_ListAll(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/providers", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("cursor") == "" {
+			testFormValues(t, r, url.Values{"limit": {"1"}})
+			fmt.Fprint(w, `{"providers": [{"id":"a"}], "metadata": {"next_cursor": "def"}}`)
+			return
+		}
+		testFormValues(t, r, url.Values{"cursor": {"def"}, "limit": {"1"}})
+		fmt.Fprint(w, `{"providers": [{"id":"b"}], "metadata": {"previous_cursor": "abc"}}`)
+	})
+
+	ctx := context.Background()
+	got, err := client.Providers.ListAll(ctx, &ProvidersListOptions{Limit: 1}).Collect()
+	if err != nil {
+		t.Fatalf("Providers.ListAll returned error: %v", err)
+	}
+
+	if len(got) != 2 || *got[0].ID != "a" || *got[1].ID != "b" {
+		t.Errorf("Providers.ListAll returned %+v, want items a and b", got)
+	}
+}
+
 func TestProvidersService_Get(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -129,3 +155,23 @@ func TestProvidersService_Get(t *testing.T) {
 		return resp, err
 	})
 }
+
+func TestProviderType_IsKnown(t *testing.T) {
+	for _, typ := range []ProviderType{ProviderTypeEmail, ProviderTypeFax, ProviderTypePost} {
+		if !typ.IsKnown() {
+			t.Errorf("ProviderType(%q).IsKnown() = false, want true", typ)
+		}
+	}
+	if ProviderType("pigeon").IsKnown() {
+		t.Error("ProviderType(\"pigeon\").IsKnown() = true, want false")
+	}
+
+	for _, m := range []ProviderMethod{ProviderMethodSingle, ProviderMethodBulk} {
+		if !m.IsKnown() {
+			t.Errorf("ProviderMethod(%q).IsKnown() = false, want true", m)
+		}
+	}
+	if ProviderMethod("carrier").IsKnown() {
+		t.Error("ProviderMethod(\"carrier\").IsKnown() = true, want false")
+	}
+}
@@ -0,0 +1,137 @@
+package gocancel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+)
+
+// Rate represents the rate limit for the current client.
+type Rate struct {
+	// The number of requests per time window the client is currently limited to.
+	Limit int `json:"limit"`
+
+	// The number of remaining requests the client can make in the current
+	// time window.
+	Remaining int `json:"remaining"`
+
+	// The time at which the current rate limit window will reset.
+	Reset Timestamp `json:"reset"`
+}
+
+func (r Rate) String() string {
+	return Stringify(r)
+}
+
+// exhausted reports whether no requests are left in the current window as of
+// now.
+func (r Rate) exhausted(now time.Time) bool {
+	return r.Limit > 0 && r.Remaining <= 0 && now.Before(r.Reset.Time)
+}
+
+// parseRate parses the rate related headers of r. The second return value
+// reports whether all rate limit headers were present and valid; the zero
+// Rate is returned otherwise.
+func parseRate(r *http.Response) (Rate, bool) {
+	limit, err := strconv.Atoi(r.Header.Get(headerRateLimit))
+	if err != nil {
+		return Rate{}, false
+	}
+	remaining, err := strconv.Atoi(r.Header.Get(headerRateRemaining))
+	if err != nil {
+		return Rate{}, false
+	}
+	reset, err := strconv.ParseInt(r.Header.Get(headerRateReset), 10, 64)
+	if err != nil {
+		return Rate{}, false
+	}
+
+	return Rate{Limit: limit, Remaining: remaining, Reset: Timestamp{time.Unix(reset, 0)}}, true
+}
+
+// RateLimitError occurs when the API responds with 429 Too Many Requests.
+type RateLimitError struct {
+	Rate Rate   // Rate specifies the last known rate limit for the client
+	Err  *Error // underlying API error
+}
+
+func (e *RateLimitError) Error() string {
+	msg := apiErrorString(e.Err, "rate limit exceeded")
+	if e.Rate.Reset.IsZero() {
+		return msg
+	}
+	return fmt.Sprintf("%v; rate limit resets at %v", msg, e.Rate.Reset)
+}
+
+// Unwrap returns the underlying API error.
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns whether the provided error equals this error. The zero
+// RateLimitError matches any RateLimitError.
+func (e *RateLimitError) Is(target error) bool {
+	v, ok := target.(*RateLimitError)
+	if !ok {
+		return false
+	}
+	if *v == (RateLimitError{}) {
+		return true
+	}
+
+	return e.Rate.Limit == v.Rate.Limit &&
+		e.Rate.Remaining == v.Rate.Remaining &&
+		e.Rate.Reset.Equal(v.Rate.Reset) &&
+		(v.Err == nil || e.Err.Is(v.Err))
+}
+
+// SetRateLimitWait is a client option that makes the client wait for the
+// current rate limit window to reset, instead of sending a request that is
+// known to be rejected because the quota is exhausted.
+func SetRateLimitWait(wait bool) ClientOpt {
+	return func(c *Client) error {
+		c.rateLimitWait = wait
+		return nil
+	}
+}
+
+// Rate returns the rate limit as of the most recent API response. The zero
+// value is returned if no rate limit information has been received yet.
+func (c *Client) Rate() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rate
+}
+
+// updateRate records the rate limit of the given response.
+func (c *Client) updateRate(rate Rate) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	c.rate = rate
+}
+
+// waitForRate blocks until the current rate limit window has been reset if
+// the known quota is exhausted and the client is configured to wait.
+func (c *Client) waitForRate(ctx context.Context) error {
+	if !c.rateLimitWait {
+		return nil
+	}
+
+	rate := c.Rate()
+	now := time.Now()
+	if !rate.exhausted(now) {
+		return nil
+	}
+
+	return sleep(ctx, rate.Reset.Sub(now))
+}
@@ -0,0 +1,181 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponse_populateRate(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+	r.Header.Set(headerRateLimit, "60")
+	r.Header.Set(headerRateRemaining, "59")
+	r.Header.Set(headerRateReset, "1622116145")
+
+	response := newResponse(r)
+	want := Rate{Limit: 60, Remaining: 59, Reset: Timestamp{time.Unix(1622116145, 0)}}
+	if got := response.Rate; got.Limit != want.Limit || got.Remaining != want.Remaining || !got.Reset.Equal(want.Reset) {
+		t.Errorf("Response.Rate = %v, want %v", got, want)
+	}
+}
+
+func TestResponse_populateRate_absent(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+
+	if _, ok := parseRate(r); ok {
+		t.Errorf("parseRate reported rate headers for a response without them")
+	}
+	if got := newResponse(r).Rate; got != (Rate{}) {
+		t.Errorf("Response.Rate = %v, want zero value", got)
+	}
+}
+
+func TestResponse_populateRate_invalid(t *testing.T) {
+	r := &http.Response{Header: http.Header{}}
+	r.Header.Set(headerRateLimit, "60")
+	r.Header.Set(headerRateRemaining, "59")
+	r.Header.Set(headerRateReset, "soon")
+
+	if _, ok := parseRate(r); ok {
+		t.Errorf("parseRate reported rate headers for a response with an invalid reset")
+	}
+	if got := newResponse(r).Rate; got != (Rate{}) {
+		t.Errorf("Response.Rate = %v, want zero value", got)
+	}
+}
+
+func TestDo_invalidRateKeepsClientRate(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "60")
+		w.Header().Set(headerRateRemaining, "")
+		w.Header().Set(headerRateReset, "1622116145")
+		fmt.Fprint(w, `{}`)
+	})
+
+	want := Rate{Limit: 60, Remaining: 42, Reset: Timestamp{time.Unix(1622116145, 0)}}
+	client.updateRate(want)
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	if _, err := client.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+
+	if got := client.Rate(); got != want {
+		t.Errorf("Client.Rate = %v, want %v", got, want)
+	}
+}
+
+func TestDo_updatesClientRate(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "60")
+		w.Header().Set(headerRateRemaining, "42")
+		w.Header().Set(headerRateReset, "1622116145")
+		fmt.Fprint(w, `{}`)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	if _, err := client.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+
+	if got := client.Rate(); got.Limit != 60 || got.Remaining != 42 {
+		t.Errorf("Client.Rate = %v, want limit 60 and 42 remaining", got)
+	}
+}
+
+func TestCheckResponse_rateLimit(t *testing.T) {
+	res := &http.Response{
+		Request:    &http.Request{},
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"error": {"code": "rate_limited", "message": "Too many requests"}}`)),
+	}
+	res.Header.Set(headerRateLimit, "60")
+	res.Header.Set(headerRateRemaining, "0")
+	res.Header.Set(headerRateReset, "1622116145")
+
+	err := CheckResponse(res)
+
+	var rerr *RateLimitError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("CheckResponse returned %#v, want *RateLimitError", err)
+	}
+
+	want := &RateLimitError{
+		Rate: Rate{Limit: 60, Remaining: 0, Reset: Timestamp{time.Unix(1622116145, 0)}},
+		Err: &Error{
+			Response: res,
+			Code:     "rate_limited",
+			Message:  "Too many requests",
+		},
+	}
+	if !errors.Is(err, want) {
+		t.Errorf("Error = %#v, want %#v", err, want)
+	}
+
+	var aerr *Error
+	if !errors.As(err, &aerr) || aerr.Code != "rate_limited" {
+		t.Errorf("RateLimitError does not unwrap to the underlying *Error, got %#v", aerr)
+	}
+
+	res.Header.Del(headerRateReset)
+	res.Body = ioutil.NopCloser(strings.NewReader(`{"error": {"code": "rate_limited", "message": "Too many requests"}}`))
+	if err := CheckResponse(res); strings.Contains(err.Error(), "resets at") {
+		t.Errorf("Error = %q, want no reset time without rate limit headers", err)
+	}
+}
+
+func TestBareDo_rateLimitWait(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRateLimitWait(true)(client); err != nil {
+		t.Fatalf("SetRateLimitWait returned error: %v", err)
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	const wait = 50 * time.Millisecond
+	client.updateRate(Rate{Limit: 60, Remaining: 0, Reset: Timestamp{time.Now().Add(wait)}})
+
+	start := time.Now()
+	req, _ := client.NewRequest("GET", ".", nil)
+	if _, err := client.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Errorf("Do returned after %v, want it to wait at least %v", elapsed, wait)
+	}
+}
+
+func TestBareDo_rateLimitWait_canceled(t *testing.T) {
+	client, _, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRateLimitWait(true)(client); err != nil {
+		t.Fatalf("SetRateLimitWait returned error: %v", err)
+	}
+	client.updateRate(Rate{Limit: 60, Remaining: 0, Reset: Timestamp{time.Now().Add(time.Hour)}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	if _, err := client.Do(ctx, req, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Do returned %v, want %v", err, context.Canceled)
+	}
+}
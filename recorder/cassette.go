@@ -0,0 +1,97 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cassette is a sequence of recorded HTTP interactions, stored as a JSON
+// file.
+type Cassette struct {
+	// Path is the file the cassette is loaded from and saved to.
+	Path string `json:"-"`
+
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response to it.
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+
+	replayed bool
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+
+	// BodyEncoding is "base64" for bodies that are not valid UTF-8.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+
+	// BodyEncoding is "base64" for bodies that are not valid UTF-8.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// LoadCassette reads the cassette stored at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{Path: path}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Save writes the cassette to its path, creating the parent directories as
+// needed. The file is replaced atomically, so a failed save leaves the
+// previous recording intact.
+func (c *Cassette) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(c.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.Path)
+}
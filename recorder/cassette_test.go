@@ -0,0 +1,62 @@
+package recorder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCassette_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cassette.json")
+
+	want := &Cassette{
+		Path: path,
+		Interactions: []*Interaction{{
+			Request: &Request{
+				Method: "GET",
+				URL:    "https://app.gocxl.com/api/v1/letters/1/document",
+			},
+			Response: &Response{
+				StatusCode:   http.StatusOK,
+				Header:       http.Header{"Content-Type": {"application/octet-stream"}},
+				Body:         "JVBERg==",
+				BodyEncoding: bodyEncodingBase64,
+			},
+		}},
+	}
+
+	if err := want.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	got, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadCassette returned %+v, want %+v", got, want)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat returned error: %v", err)
+	}
+	if fi.Mode().Perm() != 0644 {
+		t.Errorf("Cassette has mode %v, want %v", fi.Mode().Perm(), os.FileMode(0644))
+	}
+}
+
+func TestLoadCassette_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCassette(path); err == nil {
+		t.Error("LoadCassette returned no error for an invalid cassette")
+	}
+}
@@ -0,0 +1,122 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Matcher reports whether an outgoing request r matches the recorded request
+// i. Both requests have been scrubbed.
+type Matcher func(r, i *Request) bool
+
+// DefaultMatcher matches requests on their method, path, query and body, see
+// MatchBody.
+var DefaultMatcher = MatchAll(MatchMethod, MatchPath, MatchQuery, MatchBody)
+
+// MatchAll returns a Matcher matching requests that match all of matchers.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(r, i *Request) bool {
+		for _, m := range matchers {
+			if !m(r, i) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchMethod matches requests with the same method.
+func MatchMethod(r, i *Request) bool {
+	return r.Method == i.Method
+}
+
+// MatchPath matches requests with the same URL path.
+func MatchPath(r, i *Request) bool {
+	ru, rerr := url.Parse(r.URL)
+	iu, ierr := url.Parse(i.URL)
+	if rerr != nil || ierr != nil {
+		return r.URL == i.URL
+	}
+
+	return ru.Path == iu.Path
+}
+
+// MatchQuery matches requests with the same query parameters, regardless of
+// their order.
+func MatchQuery(r, i *Request) bool {
+	ru, rerr := url.Parse(r.URL)
+	iu, ierr := url.Parse(i.URL)
+	if rerr != nil || ierr != nil {
+		return r.URL == i.URL
+	}
+
+	return reflect.DeepEqual(ru.Query(), iu.Query())
+}
+
+// MatchBody matches requests with the same body. JSON bodies are compared
+// semantically, so formatting and key order do not matter. Multipart bodies,
+// such as proof of ID uploads, are compared part by part, ignoring their
+// randomly generated boundaries.
+func MatchBody(r, i *Request) bool {
+	if r.Body == i.Body {
+		return true
+	}
+
+	if rp, ok := multipartParts(r); ok {
+		ip, ok := multipartParts(i)
+		return ok && reflect.DeepEqual(rp, ip)
+	}
+
+	var rv, iv interface{}
+	if json.Unmarshal([]byte(r.Body), &rv) != nil || json.Unmarshal([]byte(i.Body), &iv) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(rv, iv)
+}
+
+// part is a part of a multipart body.
+type part struct {
+	Header textproto.MIMEHeader
+	Body   []byte
+}
+
+// multipartParts returns the parts of the multipart body of r. It returns
+// false if r does not have a valid multipart body.
+func multipartParts(r *Request) ([]part, bool) {
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mt, "multipart/") {
+		return nil, false
+	}
+
+	body, err := decodeBody(r.Body, r.BodyEncoding)
+	if err != nil {
+		return nil, false
+	}
+
+	var parts []part
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return parts, true
+		}
+		if err != nil {
+			return nil, false
+		}
+
+		data, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, part{Header: p.Header, Body: data})
+	}
+}
@@ -0,0 +1,88 @@
+package recorder
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	recorded := &Request{
+		Method: "POST",
+		URL:    "https://app.gocxl.com/api/v1/letters?limit=10&cursor=abc",
+		Body:   `{"organization_id":"1","locale":"nl-NL"}`,
+	}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		request *Request
+		want    bool
+	}{
+		{"method", MatchMethod, &Request{Method: "POST"}, true},
+		{"method mismatch", MatchMethod, &Request{Method: "GET"}, false},
+		{"path", MatchPath, &Request{URL: "https://app.gocxl.com/api/v1/letters"}, true},
+		{"path mismatch", MatchPath, &Request{URL: "https://app.gocxl.com/api/v1/letters/1"}, false},
+		{"query order", MatchQuery, &Request{URL: "https://app.gocxl.com/api/v1/letters?cursor=abc&limit=10"}, true},
+		{"query mismatch", MatchQuery, &Request{URL: "https://app.gocxl.com/api/v1/letters?limit=10"}, false},
+		{"body key order", MatchBody, &Request{Body: `{ "locale": "nl-NL", "organization_id": "1" }`}, true},
+		{"body mismatch", MatchBody, &Request{Body: `{"organization_id":"2","locale":"nl-NL"}`}, false},
+		{"body not JSON", MatchBody, &Request{Body: "organization_id=1"}, false},
+		{"all", DefaultMatcher, &Request{
+			Method: "POST",
+			URL:    "https://app.gocxl.com/api/v1/letters?cursor=abc&limit=10",
+			Body:   `{"locale":"nl-NL","organization_id":"1"}`,
+		}, true},
+		{"all mismatch", DefaultMatcher, &Request{
+			Method: "POST",
+			URL:    "https://app.gocxl.com/api/v1/letters?cursor=abc&limit=10",
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher(tt.request, recorded); got != tt.want {
+				t.Errorf("matcher returned %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// multipartRequest returns a recorded request uploading content as a file,
+// with a random multipart boundary.
+func multipartRequest(t *testing.T, content []byte) *Request {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "id.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile returned error: %v", err)
+	}
+	fw.Write(content)
+	mw.Close()
+
+	r := &Request{
+		Method: "POST",
+		URL:    "https://app.gocxl.com/api/v1/proof_of_ids",
+		Header: http.Header{"Content-Type": {mw.FormDataContentType()}},
+	}
+	r.Body, r.BodyEncoding = encodeBody(buf.Bytes())
+	return r
+}
+
+func TestMatchBody_multipart(t *testing.T) {
+	content := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}
+	recorded := multipartRequest(t, content)
+
+	if r := multipartRequest(t, content); !MatchBody(r, recorded) {
+		t.Error("MatchBody did not match an upload with another boundary")
+	}
+	if r := multipartRequest(t, []byte("other")); MatchBody(r, recorded) {
+		t.Error("MatchBody matched an upload of another file")
+	}
+	if MatchBody(&Request{Body: `{"file":"id.png"}`}, recorded) {
+		t.Error("MatchBody matched a request without multipart body")
+	}
+}
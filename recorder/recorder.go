@@ -0,0 +1,297 @@
+// Package recorder provides an http.RoundTripper that records the HTTP
+// interactions of a gocancel.Client to cassette files and replays them later,
+// so integration tests can run offline against captured API traffic.
+//
+// A typical test creates a Recorder for a cassette, passes its client to
+// gocancel.NewClient and stops the recorder when done:
+//
+//	rec, err := recorder.New("testdata/letters.json", recorder.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	client := gocancel.NewClient(rec.Client())
+//
+// Sensitive data is scrubbed from the interactions before they are saved, see
+// Scrubber.
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode determines whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeAuto replays the cassette if it exists, and records it otherwise.
+	ModeAuto Mode = iota
+
+	// ModeRecord makes real requests and records them, replacing the
+	// cassette when the recorder is stopped.
+	ModeRecord
+
+	// ModeReplay replays the cassette, failing requests that were not
+	// recorded. The cassette must exist.
+	ModeReplay
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeAuto:
+		return "auto"
+	case ModeRecord:
+		return "record"
+	case ModeReplay:
+		return "replay"
+	}
+	return "Mode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ErrInteractionNotFound is returned when replaying a request that does not
+// match any of the recorded interactions that have not been replayed yet.
+var ErrInteractionNotFound = errors.New("recorder: interaction not found")
+
+// bodyEncodingBase64 marks recorded bodies that are not valid UTF-8, and are
+// therefore stored base64 encoded.
+const bodyEncodingBase64 = "base64"
+
+// Recorder is an http.RoundTripper recording interactions to, or replaying
+// them from, a cassette. It is safe for concurrent use, although the order of
+// concurrent interactions is not deterministic.
+type Recorder struct {
+	// Transport makes the real requests while recording. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Matcher selects the recorded interaction to replay for a request.
+	// Defaults to DefaultMatcher.
+	Matcher Matcher
+
+	// Scrubbers remove sensitive data from the recorded interactions.
+	// Defaults to DefaultScrubbers when nil, use an empty slice to disable
+	// scrubbing.
+	Scrubbers []Scrubber
+
+	mode     Mode
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// New returns a Recorder for the cassette at path. In ModeAuto the mode of
+// the recorder is ModeReplay if the cassette exists and ModeRecord otherwise.
+func New(path string, mode Mode) (*Recorder, error) {
+	if mode == ModeAuto {
+		mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			mode = ModeReplay
+		}
+	}
+
+	r := &Recorder{mode: mode, cassette: &Cassette{Path: path}}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, fmt.Errorf("recorder: loading cassette: %w", err)
+		}
+		r.cassette = c
+	default:
+		return nil, fmt.Errorf("recorder: invalid mode %v", mode)
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode of the recorder, either ModeRecord or ModeReplay.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the cassette when recording. The recorder should not be used
+// afterwards.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save()
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request:  newRequest(req, body),
+		Response: &Response{StatusCode: resp.StatusCode, Header: resp.Header.Clone()},
+	}
+	i.Response.Body, i.Response.BodyEncoding = encodeBody(respBody)
+	i.Response.Header.Del("Content-Length")
+	r.scrub(i)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	want := &Interaction{Request: newRequest(req, body)}
+	r.scrub(want)
+
+	match := r.Matcher
+	if match == nil {
+		match = DefaultMatcher
+	}
+
+	r.mu.Lock()
+	var found *Interaction
+	for _, i := range r.cassette.Interactions {
+		if !i.replayed && match(want.Request, i.Request) {
+			i.replayed = true
+			found = i
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, req.URL)
+	}
+
+	respBody, err := decodeBody(found.Response.Body, found.Response.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: decoding recorded body: %w", err)
+	}
+
+	header := found.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(respBody)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.Response.StatusCode, http.StatusText(found.Response.StatusCode)),
+		StatusCode:    found.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) scrub(i *Interaction) {
+	scrubbers := r.Scrubbers
+	if scrubbers == nil {
+		scrubbers = DefaultScrubbers
+	}
+
+	for _, s := range scrubbers {
+		s(i)
+	}
+}
+
+// readBody reads and closes the body of req, if any.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	defer req.Body.Close()
+	return ioutil.ReadAll(req.Body)
+}
+
+// newRequest returns the recorded form of req.
+func newRequest(req *http.Request, body []byte) *Request {
+	rr := &Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	rr.Body, rr.BodyEncoding = encodeBody(body)
+
+	return rr
+}
+
+// encodeBody returns body as a string, base64 encoding it when it is not
+// valid UTF-8 text.
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(body), bodyEncodingBase64
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case bodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(body)
+	}
+
+	return nil, fmt.Errorf("unknown body encoding %q", encoding)
+}
@@ -0,0 +1,164 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+func TestRecorder_recordAndReplay(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Server got Authorization %q, want %q", got, "Bearer token")
+		}
+		fmt.Fprint(w, `{"letter":{"id":"1","email":"jane@example.org","address":{"address_line1":"Main Street 1"}}}`)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	getLetter := func(rec *Recorder) *gocancel.Letter {
+		t.Helper()
+
+		client, err := gocancel.New(rec.Client(),
+			gocancel.SetBaseURL(ts.URL+"/"),
+			gocancel.SetRequestHeaders(map[string]string{"Authorization": "Bearer token"}))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		letter, _, err := client.Letters.Get(context.Background(), "1")
+		if err != nil {
+			t.Fatalf("Letters.Get returned error: %v", err)
+		}
+
+		if err := rec.Stop(); err != nil {
+			t.Fatalf("Stop returned error: %v", err)
+		}
+
+		return letter
+	}
+
+	rec, err := New(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if rec.Mode() != ModeRecord {
+		t.Errorf("Mode = %v, want %v", rec.Mode(), ModeRecord)
+	}
+
+	// The recorded response is passed through unscrubbed.
+	if letter := getLetter(rec); *letter.Email != "jane@example.org" {
+		t.Errorf("Recorded letter has email %q, want %q", *letter.Email, "jane@example.org")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading cassette returned error: %v", err)
+	}
+	for _, secret := range []string{"Bearer token", "jane@example.org", "Main Street 1"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	rec, err = New(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if rec.Mode() != ModeReplay {
+		t.Errorf("Mode = %v, want %v", rec.Mode(), ModeReplay)
+	}
+
+	letter := getLetter(rec)
+	if *letter.ID != "1" || *letter.Email != RedactedEmail || *letter.Address.AddressLine1 != Redacted {
+		t.Errorf("Replayed letter = %v", letter)
+	}
+
+	if requests != 1 {
+		t.Errorf("Server got %d requests, want 1", requests)
+	}
+}
+
+func TestRecorder_replayNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := &Cassette{Path: path, Interactions: []*Interaction{{
+		Request:  &Request{Method: "GET", URL: "https://app.gocxl.com/api/v1/letters/1"},
+		Response: &Response{StatusCode: http.StatusOK, Body: `{"letter":{"id":"1"}}`},
+	}}}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	rec, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	client := gocancel.NewClient(rec.Client())
+	ctx := context.Background()
+
+	if _, _, err := client.Letters.Get(ctx, "1"); err != nil {
+		t.Fatalf("Letters.Get returned error: %v", err)
+	}
+
+	// Every interaction is replayed once.
+	_, _, err = client.Letters.Get(ctx, "1")
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("Letters.Get returned %v, want ErrInteractionNotFound", err)
+	}
+
+	_, _, err = client.Letters.Get(ctx, "2")
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("Letters.Get returned %v, want ErrInteractionNotFound", err)
+	}
+}
+
+func TestRecorder_binaryBody(t *testing.T) {
+	content := []byte{0x25, 0x50, 0x44, 0x46, 0xff, 0xfe, 0x00}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	for _, mode := range []Mode{ModeRecord, ModeReplay} {
+		rec, err := New(path, mode)
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		resp, err := rec.Client().Get(ts.URL + "/document")
+		if err != nil {
+			t.Fatalf("%v: Get returned error: %v", mode, err)
+		}
+		got, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if string(got) != string(content) {
+			t.Errorf("%v: body = %v, want %v", mode, got, content)
+		}
+
+		if err := rec.Stop(); err != nil {
+			t.Fatalf("Stop returned error: %v", err)
+		}
+	}
+}
+
+func TestNew_missingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	if err == nil {
+		t.Error("New returned no error for a missing cassette")
+	}
+}
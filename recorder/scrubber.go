@@ -0,0 +1,110 @@
+package recorder
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gocancel/gocancel-go"
+	"github.com/gocancel/gocancel-go/internal/redact"
+)
+
+// Redacted replaces scrubbed values.
+const Redacted = "REDACTED"
+
+// RedactedEmail replaces scrubbed email addresses. It is a valid address, so
+// scrubbed interactions still decode and validate.
+const RedactedEmail = "redacted@example.com"
+
+// Scrubber removes sensitive data from an interaction before it is saved to a
+// cassette. Outgoing requests are scrubbed as well before they are matched
+// against the recorded ones during replay, so a Scrubber must be idempotent.
+// The Response of the interaction is nil while scrubbing outgoing requests.
+type Scrubber func(i *Interaction)
+
+// DefaultScrubbers remove credentials, email addresses and the personal data
+// in the JSON fields listed by gocancel.DefaultRedactedFields, such as
+// signature data, postal addresses and letter parameters.
+var DefaultScrubbers = []Scrubber{
+	ScrubHeaders("Authorization", "Cookie", "Set-Cookie"),
+	ScrubEmails,
+	ScrubJSONFields(gocancel.DefaultRedactedFields...),
+}
+
+// ScrubHeaders returns a Scrubber replacing the values of the given request
+// and response headers.
+func ScrubHeaders(names ...string) Scrubber {
+	return func(i *Interaction) {
+		for _, name := range names {
+			scrubHeader(i.Request.Header, name)
+			if i.Response != nil {
+				scrubHeader(i.Response.Header, name)
+			}
+		}
+	}
+}
+
+func scrubHeader(h http.Header, name string) {
+	if values := h.Values(name); len(values) > 0 {
+		h.Set(name, Redacted)
+	}
+}
+
+var emailRE = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// ScrubEmails replaces the email addresses in request URLs and in request and
+// response bodies with RedactedEmail.
+func ScrubEmails(i *Interaction) {
+	i.Request.URL = emailRE.ReplaceAllString(i.Request.URL, RedactedEmail)
+	i.Request.Body = emailRE.ReplaceAllString(i.Request.Body, RedactedEmail)
+	if i.Response != nil {
+		i.Response.Body = emailRE.ReplaceAllString(i.Response.Body, RedactedEmail)
+	}
+}
+
+// ScrubJSONFields returns a Scrubber replacing the values of object fields
+// with the given names, at any depth, in JSON request and response bodies.
+// Names are matched case-insensitively. The strings within a value are
+// replaced with Redacted, or RedactedEmail for email addresses, keeping its
+// shape, so the scrubbed body still decodes into the same types.
+func ScrubJSONFields(fields ...string) Scrubber {
+	w := &redact.Walker{
+		Fields: redact.NewFields(fields...),
+		Mask:   redactValue,
+	}
+
+	return func(i *Interaction) {
+		i.Request.Body = scrubJSON(w, i.Request.Body)
+		if i.Response != nil {
+			i.Response.Body = scrubJSON(w, i.Response.Body)
+		}
+	}
+}
+
+// scrubJSON returns body with the values of the fields of w redacted. Bodies
+// that are not JSON, or do not contain the fields, are returned unchanged.
+func scrubJSON(w *redact.Walker, body string) string {
+	data, _, _ := w.JSON([]byte(body))
+	return string(data)
+}
+
+// redactValue returns v with all its strings replaced by Redacted, or by
+// RedactedEmail for email addresses.
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if emailRE.MatchString(v) {
+			return RedactedEmail
+		}
+		return Redacted
+	case map[string]interface{}:
+		for k, fv := range v {
+			v[k] = redactValue(fv)
+		}
+	case []interface{}:
+		for i, ev := range v {
+			v[i] = redactValue(ev)
+		}
+	}
+
+	return v
+}
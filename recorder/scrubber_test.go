@@ -0,0 +1,65 @@
+package recorder
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDefaultScrubbers(t *testing.T) {
+	i := &Interaction{
+		Request: &Request{
+			Method: "GET",
+			URL:    "https://app.gocxl.com/api/v1/letters?email=jane%40example.org&sandbox_email=jane@example.org",
+			Header: http.Header{"Authorization": {"Bearer token"}},
+			Body:   `{"signature_data":"data:image/png;base64,abc","parameters":{"name":"Jane"}}`,
+		},
+		Response: &Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Set-Cookie": {"session=secret"}},
+			Body:       `{"letter":{"id":"1","count":12345678901234567890,"email":"jane@example.org","address":{"address_line1":"Main Street 1","lines":["a","b"]}}}`,
+		},
+	}
+
+	for _, s := range DefaultScrubbers {
+		s(i)
+	}
+
+	if got := i.Request.Header.Get("Authorization"); got != Redacted {
+		t.Errorf("Authorization = %q, want %q", got, Redacted)
+	}
+	if got := i.Response.Header.Get("Set-Cookie"); got != Redacted {
+		t.Errorf("Set-Cookie = %q, want %q", got, Redacted)
+	}
+
+	wantURL := "https://app.gocxl.com/api/v1/letters?email=jane%40example.org&sandbox_email=" + RedactedEmail
+	if i.Request.URL != wantURL {
+		t.Errorf("Request URL = %q, want %q", i.Request.URL, wantURL)
+	}
+
+	wantBody := `{"parameters":{"name":"REDACTED"},"signature_data":"REDACTED"}`
+	if i.Request.Body != wantBody {
+		t.Errorf("Request body = %s, want %s", i.Request.Body, wantBody)
+	}
+
+	wantBody = `{"letter":{"address":{"address_line1":"REDACTED","lines":["REDACTED","REDACTED"]},"count":12345678901234567890,"email":"redacted@example.com","id":"1"}}`
+	if i.Response.Body != wantBody {
+		t.Errorf("Response body = %s, want %s", i.Response.Body, wantBody)
+	}
+}
+
+func TestScrubJSONFields_unchanged(t *testing.T) {
+	bodies := []string{
+		"",
+		"not json",
+		`{ "id": "1" }`,
+	}
+
+	for _, body := range bodies {
+		i := &Interaction{Request: &Request{Body: body}}
+		ScrubJSONFields("address")(i)
+
+		if i.Request.Body != body {
+			t.Errorf("Scrubbing %q changed it to %q", body, i.Request.Body)
+		}
+	}
+}
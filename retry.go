@@ -0,0 +1,229 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRetryAfter     = "Retry-After"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// RetryPolicy configures how failed requests are retried by the client.
+// Requests are retried when the API responds with 429 Too Many Requests or a
+// 5xx server error, and on transient network errors. Only idempotent
+// requests are retried, unless the request carries an Idempotency-Key header.
+// Such a header is generated for the POST and PATCH requests of service
+// methods when retries are enabled, see WithIdempotencyKey.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the initial
+	// request. A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the backoff used before the first retry. Subsequent
+	// retries double the backoff until MaxBackoff is reached.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the backoff between two attempts. A
+	// Retry-After header asking for a longer wait stops the retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a sensible retry policy for most API consumers.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+}
+
+// SetRetryPolicy is a client option for enabling automatic retries using the
+// provided policy.
+func SetRetryPolicy(p RetryPolicy) ClientOpt {
+	return func(c *Client) error {
+		if p.MinBackoff < 0 || p.MaxBackoff < 0 {
+			return errors.New("retry policy backoff must not be negative")
+		}
+		if p.MaxBackoff < p.MinBackoff {
+			p.MaxBackoff = p.MinBackoff
+		}
+
+		c.retryPolicy = &p
+		return nil
+	}
+}
+
+// backoff returns the jittered delay before the given retry, where retry is
+// 1 for the first retry.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// Use "equal jitter": half of the delay is fixed, the other half random.
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1)) //nolint:gosec
+}
+
+// canRetry reports whether req may be sent again for the given attempt.
+func (p *RetryPolicy) canRetry(req *http.Request, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if !isIdempotent(req) {
+		return false
+	}
+
+	// A consumed body can only be sent again if it can be rebuilt.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return true
+}
+
+// isIdempotent reports whether req can safely be sent more than once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get(headerIdempotencyKey) != ""
+}
+
+// retryableStatus reports whether a response with the given status code is
+// worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryableError reports whether err, returned by the underlying HTTP client,
+// is a transient network error. Errors caused by the context of the request
+// being done are not, even though context.DeadlineExceeded is a net.Error.
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		err = uerr.Err
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+// parseRetryAfter parses the Retry-After header of r, which is either a number
+// of seconds or an HTTP date. It returns false if the header is absent or
+// malformed.
+func parseRetryAfter(r *http.Response) (time.Duration, bool) {
+	v := r.Header.Get(headerRetryAfter)
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// retryDelay returns how long the API asked us to wait before retrying r. It
+// falls back to the rate limit reset time for rate limited responses without
+// a Retry-After header.
+func retryDelay(r *http.Response) (time.Duration, bool) {
+	if d, ok := parseRetryAfter(r); ok {
+		return d, true
+	}
+
+	if r.StatusCode == http.StatusTooManyRequests {
+		if rate, ok := parseRate(r); ok && rate.exhausted(time.Now()) {
+			return time.Until(rate.Reset.Time), true
+		}
+	}
+
+	return 0, false
+}
+
+// rewindBody prepares req to be sent again by rebuilding its body.
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+
+	req.Body = body
+	return nil
+}
+
+// drainBody reads a bounded part of body and closes it, so the underlying
+// connection can be reused for the next attempt.
+func drainBody(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	body.Close()
+}
+
+// sleep pauses for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,307 @@
+package gocancel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+}
+
+func TestBareDo_retriesServerErrors(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	var calls int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"A":"a"}`)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Server received %d requests, want 2", calls)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("Response.Attempts = %d, want 2", resp.Attempts)
+	}
+}
+
+func TestBareDo_retriesExhausted(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	var calls int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	if err == nil {
+		t.Fatal("Expected HTTP 502 error, got no error.")
+	}
+
+	if calls != testRetryPolicy.MaxAttempts {
+		t.Errorf("Server received %d requests, want %d", calls, testRetryPolicy.MaxAttempts)
+	}
+	if resp.Attempts != testRetryPolicy.MaxAttempts {
+		t.Errorf("Response.Attempts = %d, want %d", resp.Attempts, testRetryPolicy.MaxAttempts)
+	}
+}
+
+func TestBareDo_noRetryPolicy(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	resp, _ := client.Do(context.Background(), req, nil)
+
+	if calls != 1 {
+		t.Errorf("Server received %d requests, want 1", calls)
+	}
+	if resp.Attempts != 1 {
+		t.Errorf("Response.Attempts = %d, want 1", resp.Attempts)
+	}
+}
+
+func TestBareDo_retryAfterTooLong(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	var calls int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	if _, err := client.Do(context.Background(), req, nil); err == nil {
+		t.Fatal("Expected HTTP 429 error, got no error.")
+	}
+
+	if calls != 1 {
+		t.Errorf("Server received %d requests, want 1", calls)
+	}
+}
+
+func TestBareDo_retryNonIdempotent(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	var calls int
+	var bodies []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls == 1 {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx := context.Background()
+	input := &LetterRequest{OrganizationID: "foo"}
+
+	req, _ := client.NewRequest("POST", ".", input)
+	if _, err := client.Do(ctx, req, nil); err == nil {
+		t.Fatal("Expected HTTP 500 error, got no error.")
+	}
+	if calls != 1 {
+		t.Errorf("Server received %d requests without idempotency key, want 1", calls)
+	}
+
+	calls, bodies = 0, nil
+	req, _ = client.NewRequest("POST", ".", input)
+	req.Header.Set("Idempotency-Key", "k")
+	if _, err := client.Do(ctx, req, nil); err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Server received %d requests with idempotency key, want 2", calls)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("Request bodies = %q, want two identical non-empty bodies", bodies)
+	}
+}
+
+func TestBareDo_retriesNetworkErrors(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	if err := SetRetryPolicy(testRetryPolicy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	var calls int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatalf("Hijack returned error: %v", err)
+			}
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("Do returned unexpected error: %v", err)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("Response.Attempts = %d, want 2", resp.Attempts)
+	}
+}
+
+func TestBareDo_retryCanceledContext(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	policy := testRetryPolicy
+	policy.MinBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+	if err := SetRetryPolicy(policy)(client); err != nil {
+		t.Fatalf("SetRetryPolicy returned error: %v", err)
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := client.NewRequest("GET", ".", nil)
+	_, err := client.Do(ctx, req, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want bool
+	}{
+		{&url.Error{Op: "Get", URL: "/", Err: io.ErrUnexpectedEOF}, true},
+		{&url.Error{Op: "Get", URL: "/", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		// context.DeadlineExceeded implements net.Error, but waiting longer
+		// will not help a request whose context is done.
+		{&url.Error{Op: "Get", URL: "/", Err: context.DeadlineExceeded}, false},
+		{&url.Error{Op: "Get", URL: "/", Err: context.Canceled}, false},
+		{errors.New("invalid request"), false},
+	}
+
+	for _, tc := range testcases {
+		if got := retryableError(tc.err); got != tc.want {
+			t.Errorf("retryableError(%v) = %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestSetRetryPolicy_invalid(t *testing.T) {
+	_, err := New(nil, SetRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: -1}))
+	if err == nil {
+		t.Error("Expected error to be returned.")
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	testcases := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, tc := range testcases {
+		for i := 0; i < 20; i++ {
+			if got := p.backoff(tc.retry); got < tc.min || got > tc.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tc.retry, got, tc.min, tc.max)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	testcases := map[string]struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		"absent":    {header: "", wantOK: false},
+		"seconds":   {header: "120", want: 2 * time.Minute, wantOK: true},
+		"negative":  {header: "-5", want: 0, wantOK: true},
+		"past date": {header: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+		"malformed": {header: "soon", wantOK: false},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			r := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				r.Header.Set("Retry-After", tc.header)
+			}
+
+			got, ok := parseRetryAfter(r)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = (%v, %t), want (%v, %t)", tc.header, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ type Webhook struct {
 	Locales   []*string        `json:"locales,omitempty"`
 	Metadata  *AccountMetadata `json:"metadata,omitempty"`
 	Active    *bool            `json:"active,omitempty"`
+	Secret    *string          `json:"secret,omitempty"`
 	CreatedAt *Timestamp       `json:"created_at,omitempty"`
 	UpdatedAt *Timestamp       `json:"updated_at,omitempty"`
 }
@@ -26,6 +27,17 @@ func (w Webhook) String() string {
 	return Stringify(w)
 }
 
+// WebhookRequest represents a request for creating or updating a webhook.
+// Events and Locales are only sent when not nil, like Active, so an empty
+// list can be sent explicitly using Strings().
+type WebhookRequest struct {
+	URL      string          `json:"url,omitempty"`
+	Events   *[]string       `json:"events,omitempty"`
+	Locales  *[]string       `json:"locales,omitempty"`
+	Metadata AccountMetadata `json:"metadata,omitempty"`
+	Active   *bool           `json:"active,omitempty"`
+}
+
 type ProductsSortOptions struct {
 	CreatedAt string `url:"created_at,omitempty"`
 	UpdatedAt string `url:"updated_at,omitempty"`
@@ -59,6 +71,8 @@ type webhooksRoot struct {
 
 // List lists all webhooks
 func (s *WebhooksService) List(ctx context.Context, opts *WebhooksListOptions) ([]*Webhook, *Response, error) {
+	ctx = withOperation(ctx, "Webhooks.List")
+
 	u, err := addOptions("api/v1/webhooks", opts)
 	if err != nil {
 		return nil, nil, err
@@ -80,8 +94,52 @@ func (s *WebhooksService) List(ctx context.Context, opts *WebhooksListOptions) (
 	return root.Webhooks, resp, nil
 }
 
+// WebhooksIterator iterates over webhooks, following the pagination cursors
+// automatically.
+type WebhooksIterator struct {
+	iterator
+	page []*Webhook
+}
+
+// Value returns the current webhook. It must only be called after a call
+// to Next returned true.
+func (it *WebhooksIterator) Value() *Webhook {
+	return it.page[it.index]
+}
+
+// Collect drains the iterator and returns all remaining items.
+func (it *WebhooksIterator) Collect() ([]*Webhook, error) {
+	var items []*Webhook
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+
+	return items, it.Err()
+}
+
+// ListAll returns an iterator over all webhooks, starting at the cursor in
+// opts. Pages are fetched lazily using the limit in opts as the page size.
+func (s *WebhooksService) ListAll(ctx context.Context, opts *WebhooksListOptions) *WebhooksIterator {
+	var o WebhooksListOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	it := new(WebhooksIterator)
+	it.iterator = newIterator(ctx, o.Cursor, func(ctx context.Context, cursor string) (int, *Response, error) {
+		o.Cursor = cursor
+		page, resp, err := s.List(ctx, &o)
+		it.page = page
+		return len(page), resp, err
+	})
+
+	return it
+}
+
 // Get fetches a webhook.
 func (s *WebhooksService) Get(ctx context.Context, webhook string) (*Webhook, *Response, error) {
+	ctx = withOperation(ctx, "Webhooks.Get")
+
 	u := fmt.Sprintf("api/v1/webhooks/%s", webhook)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
@@ -96,3 +154,100 @@ func (s *WebhooksService) Get(ctx context.Context, webhook string) (*Webhook, *R
 
 	return root.Webhook, resp, nil
 }
+
+// Create creates a webhook. The returned webhook contains the secret used to
+// sign its deliveries.
+func (s *WebhooksService) Create(ctx context.Context, request *WebhookRequest) (*Webhook, *Response, error) {
+	ctx = withOperation(ctx, "Webhooks.Create")
+
+	req, err := s.client.NewRequest("POST", "api/v1/webhooks", request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(webhookRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Webhook, resp, nil
+}
+
+// Update updates a webhook.
+func (s *WebhooksService) Update(ctx context.Context, webhook string, request *WebhookRequest) (*Webhook, *Response, error) {
+	ctx = withOperation(ctx, "Webhooks.Update")
+
+	u := fmt.Sprintf("api/v1/webhooks/%s", webhook)
+	req, err := s.client.NewRequest("PUT", u, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(webhookRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Webhook, resp, nil
+}
+
+// Delete deletes a webhook.
+func (s *WebhooksService) Delete(ctx context.Context, webhook string) (*Response, error) {
+	ctx = withOperation(ctx, "Webhooks.Delete")
+
+	u := fmt.Sprintf("api/v1/webhooks/%s", webhook)
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// RollWebhookSecretRequest represents a `roll webhook secret` request.
+type RollWebhookSecretRequest struct {
+	// ExpiresIn is the number of seconds the previous secret remains valid.
+	// Deliveries are signed with both secrets until it expires.
+	ExpiresIn int `json:"expires_in,omitempty"`
+}
+
+// RollSecret replaces the signing secret of a webhook. The returned webhook
+// contains the new secret.
+func (s *WebhooksService) RollSecret(ctx context.Context, webhook string, request *RollWebhookSecretRequest) (*Webhook, *Response, error) {
+	ctx = withOperation(ctx, "Webhooks.RollSecret")
+
+	u := fmt.Sprintf("api/v1/webhooks/%s/roll_secret", webhook)
+	req, err := s.client.NewRequest("POST", u, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(webhookRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return root.Webhook, resp, nil
+}
+
+// SendWebhookTestEventRequest represents a `send webhook test event` request.
+type SendWebhookTestEventRequest struct {
+	// Event is the type of the event to send, e.g. "letter.sent".
+	Event string `json:"event,omitempty"`
+}
+
+// SendTestEvent sends a test event to the URL of a webhook.
+func (s *WebhooksService) SendTestEvent(ctx context.Context, webhook string, request *SendWebhookTestEventRequest) (*Response, error) {
+	ctx = withOperation(ctx, "Webhooks.SendTestEvent")
+
+	u := fmt.Sprintf("api/v1/webhooks/%s/test", webhook)
+	req, err := s.client.NewRequest("POST", u, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+// EventType represents the type of a webhook event, e.g. "letter.sent".
+type EventType string
+
+// This block represents the event types of the webhooks sent by GoCancel.
+const (
+	EventLetterCreated EventType = "letter.created"
+	EventLetterUpdated EventType = "letter.updated"
+	EventLetterDeleted EventType = "letter.deleted"
+	EventLetterDrafted EventType = "letter.drafted"
+	EventLetterSent    EventType = "letter.sent"
+	EventLetterFailed  EventType = "letter.failed"
+)
+
+// ErrNotLetterEvent is returned when decoding the letter of an event that is
+// not about a letter.
+var ErrNotLetterEvent = errors.New("webhook event is not a letter event")
+
+// IsLetterEvent reports whether events of this type carry a letter as data.
+func (t EventType) IsLetterEvent() bool {
+	return strings.HasPrefix(string(t), "letter.")
+}
+
+// Event represents the envelope of a webhook event sent by GoCancel.
+type Event struct {
+	ID        string             `json:"id"`
+	Type      EventType          `json:"type"`
+	CreatedAt gocancel.Timestamp `json:"created_at"`
+	AccountID string             `json:"account_id"`
+	Locale    string             `json:"locale,omitempty"`
+
+	// Data holds the raw JSON of the object the event is about. Use a typed
+	// accessor such as Letter to decode it.
+	Data json.RawMessage `json:"data"`
+}
+
+// Letter decodes the data of a letter event into a letter.
+func (e *Event) Letter() (*gocancel.Letter, error) {
+	if !e.Type.IsLetterEvent() {
+		return nil, ErrNotLetterEvent
+	}
+
+	letter := new(gocancel.Letter)
+	if err := json.Unmarshal(e.Data, letter); err != nil {
+		return nil, fmt.Errorf("decoding letter of webhook event %s: %w", e.ID, err)
+	}
+
+	return letter, nil
+}
+
+// ParseEvent decodes payload into an event without verifying its signature.
+// Use ConstructEvent for payloads received from the network.
+func ParseEvent(payload []byte) (*Event, error) {
+	e := new(Event)
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, fmt.Errorf("decoding webhook event: %w", err)
+	}
+
+	return e, nil
+}
+
+// ConstructEvent validates the payload against the Gocxl-Signature header
+// using the specified signing secret and decodes it into an event. Returns an
+// error if the signature is invalid, if the timestamp for the signature is
+// older than DefaultTolerance or if the payload could not be decoded.
+func ConstructEvent(payload []byte, header string, secret string) (*Event, error) {
+	return ConstructEventWithTolerance(payload, header, secret, DefaultTolerance)
+}
+
+// ConstructEventIgnoringTolerance validates the payload against the
+// Gocxl-Signature header using the specified signing secret and decodes it
+// into an event. Does not check the signature's timestamp.
+func ConstructEventIgnoringTolerance(payload []byte, header string, secret string) (*Event, error) {
+	return constructEvent(payload, header, secret, 0*time.Second, false)
+}
+
+// ConstructEventWithTolerance validates the payload against the
+// Gocxl-Signature header using the specified signing secret and tolerance
+// window and decodes it into an event.
+func ConstructEventWithTolerance(payload []byte, header string, secret string, tolerance time.Duration) (*Event, error) {
+	return constructEvent(payload, header, secret, tolerance, true)
+}
+
+func constructEvent(payload []byte, header string, secret string, tolerance time.Duration, enforceTolerance bool) (*Event, error) {
+	if err := validatePayload(payload, header, secret, tolerance, enforceTolerance); err != nil {
+		return nil, err
+	}
+
+	return ParseEvent(payload)
+}
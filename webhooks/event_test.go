@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testEventPayload = []byte(`{
+	"id": "evt_123",
+	"type": "letter.sent",
+	"created_at": "2021-05-27T11:49:05Z",
+	"account_id": "f172758f-7718-41f4-95d6-d3fd931e0326",
+	"locale": "nl-NL",
+	"data": {"id": "26468553-08bb-47c4-a28c-d80dec6ef3b2", "state": "sent"}
+}`)
+
+func TestParseEvent(t *testing.T) {
+	e, err := ParseEvent(testEventPayload)
+	if err != nil {
+		t.Fatalf("ParseEvent returned error: %v", err)
+	}
+
+	if e.ID != "evt_123" {
+		t.Errorf("Event.ID = %q, want %q", e.ID, "evt_123")
+	}
+	if e.Type != EventLetterSent {
+		t.Errorf("Event.Type = %q, want %q", e.Type, EventLetterSent)
+	}
+	if want := time.Date(2021, time.May, 27, 11, 49, 05, 0, time.UTC); !e.CreatedAt.Time.Equal(want) {
+		t.Errorf("Event.CreatedAt = %v, want %v", e.CreatedAt, want)
+	}
+	if e.AccountID != "f172758f-7718-41f4-95d6-d3fd931e0326" || e.Locale != "nl-NL" {
+		t.Errorf("Event has account %q and locale %q", e.AccountID, e.Locale)
+	}
+
+	if _, err := ParseEvent([]byte(`{`)); err == nil {
+		t.Errorf("Expected error from malformed payload")
+	}
+}
+
+func TestEvent_Letter(t *testing.T) {
+	e, err := ParseEvent(testEventPayload)
+	if err != nil {
+		t.Fatalf("ParseEvent returned error: %v", err)
+	}
+
+	letter, err := e.Letter()
+	if err != nil {
+		t.Fatalf("Event.Letter returned error: %v", err)
+	}
+	if letter.ID == nil || *letter.ID != "26468553-08bb-47c4-a28c-d80dec6ef3b2" {
+		t.Errorf("Event.Letter returned letter with ID %v", letter.ID)
+	}
+
+	e.Type = "organization.created"
+	if _, err := e.Letter(); err != ErrNotLetterEvent {
+		t.Errorf("Expected ErrNotLetterEvent for organization event, got %v", err)
+	}
+
+	e.Type = EventLetterSent
+	e.Data = []byte(`"not a letter"`)
+	if _, err := e.Letter(); err == nil {
+		t.Errorf("Expected error from malformed letter data")
+	}
+}
+
+func TestConstructEvent(t *testing.T) {
+	p := newSignedPayload(func(p *signedPayload) {
+		p.payload = testEventPayload
+	})
+
+	e, err := ConstructEvent(p.payload, p.header, p.secret)
+	if err != nil {
+		t.Fatalf("ConstructEvent returned error: %v", err)
+	}
+	if e.ID != "evt_123" {
+		t.Errorf("Event.ID = %q, want %q", e.ID, "evt_123")
+	}
+
+	_, err = ConstructEvent(p.payload, p.header, "wrong_secret")
+	if !errors.Is(err, ErrNoValidSignature) {
+		t.Errorf("Expected ErrNoValidSignature from wrong secret, got %v", err)
+	}
+
+	p = newSignedPayload(func(p *signedPayload) {
+		p.payload = testEventPayload
+		p.timestamp = time.Now().Add(-time.Hour)
+	})
+	if _, err := ConstructEvent(p.payload, p.header, p.secret); err != ErrTooOld {
+		t.Errorf("Expected ErrTooOld from old signature, got %v", err)
+	}
+	if _, err := ConstructEventWithTolerance(p.payload, p.header, p.secret, 2*time.Hour); err != nil {
+		t.Errorf("Received %v error when validating timestamp inside allowed timing window", err)
+	}
+	if _, err := ConstructEventIgnoringTolerance(p.payload, p.header, p.secret); err != nil {
+		t.Errorf("Received %v error when timestamp outside window but no tolerance specified", err)
+	}
+}
@@ -0,0 +1,238 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+const (
+	// SignatureHeader is the HTTP header carrying the signature of a webhook.
+	SignatureHeader = "Gocxl-Signature"
+
+	// DefaultMaxBodyBytes is the maximum size of a webhook body accepted by a
+	// Handler, unless configured otherwise.
+	DefaultMaxBodyBytes int64 = 1 << 20
+)
+
+// EventFunc handles a verified webhook event.
+type EventFunc func(ctx context.Context, e *Event) error
+
+// LetterEventFunc handles a verified webhook event about a letter.
+type LetterEventFunc func(ctx context.Context, e *Event, letter *gocancel.Letter) error
+
+// Handler is an http.Handler that receives webhooks, verifies their
+// signature, decodes them into events and dispatches them to the callbacks
+// registered for their event type. Events without a registered callback are
+// acknowledged and otherwise ignored.
+//
+// Callbacks must be registered before the handler starts serving requests.
+// A callback error results in a 500 Internal Server Error response, so
+// GoCancel will deliver the event again, unless the error carries a different
+// status code using WithStatus.
+type Handler struct {
+	// MaxBodyBytes limits the size of the accepted request bodies. Defaults to
+	// DefaultMaxBodyBytes when zero.
+	MaxBodyBytes int64
+
+	// Tolerance is the maximum age of an accepted signature. Defaults to
+	// DefaultTolerance when zero.
+	Tolerance time.Duration
+
+	// ErrorLog specifies an optional logger for errors that occur while
+	// handling webhooks. If nil, errors are not logged.
+	ErrorLog *log.Logger
+
+	// Store optionally records the IDs of handled events. Events that have
+	// already been handled are acknowledged without calling the callbacks
+	// again. If nil, every delivery is handled.
+	Store Store
+
+	secrets  []string
+	handlers map[EventType][]EventFunc
+}
+
+// NewHandler returns a new Handler verifying webhooks against the given
+// signing secrets. Multiple secrets can be given while rotating secrets.
+// NewHandler panics if no secrets are given, as the handler would reject
+// every webhook.
+func NewHandler(secrets ...string) *Handler {
+	if len(secrets) == 0 {
+		panic("webhooks: NewHandler called without signing secrets")
+	}
+
+	return &Handler{
+		secrets:  secrets,
+		handlers: make(map[EventType][]EventFunc),
+	}
+}
+
+// On registers fn to be called for events of type t. Multiple callbacks can be
+// registered for the same type, they are called in order of registration
+// until one of them returns an error.
+func (h *Handler) On(t EventType, fn EventFunc) {
+	h.handlers[t] = append(h.handlers[t], fn)
+}
+
+// OnLetterEvent registers fn to be called with the decoded letter for events
+// of type t.
+func (h *Handler) OnLetterEvent(t EventType, fn LetterEventFunc) {
+	h.On(t, func(ctx context.Context, e *Event) error {
+		letter, err := e.Letter()
+		if err != nil {
+			return WithStatus(err, http.StatusBadRequest)
+		}
+
+		return fn(ctx, e, letter)
+	})
+}
+
+// OnLetterCreated registers fn to be called for letter.created events.
+func (h *Handler) OnLetterCreated(fn LetterEventFunc) { h.OnLetterEvent(EventLetterCreated, fn) }
+
+// OnLetterUpdated registers fn to be called for letter.updated events.
+func (h *Handler) OnLetterUpdated(fn LetterEventFunc) { h.OnLetterEvent(EventLetterUpdated, fn) }
+
+// OnLetterDeleted registers fn to be called for letter.deleted events.
+func (h *Handler) OnLetterDeleted(fn LetterEventFunc) { h.OnLetterEvent(EventLetterDeleted, fn) }
+
+// OnLetterDrafted registers fn to be called for letter.drafted events.
+func (h *Handler) OnLetterDrafted(fn LetterEventFunc) { h.OnLetterEvent(EventLetterDrafted, fn) }
+
+// OnLetterSent registers fn to be called for letter.sent events.
+func (h *Handler) OnLetterSent(fn LetterEventFunc) { h.OnLetterEvent(EventLetterSent, fn) }
+
+// OnLetterFailed registers fn to be called for letter.failed events.
+func (h *Handler) OnLetterFailed(fn LetterEventFunc) { h.OnLetterEvent(EventLetterFailed, fn) }
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.error(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	maxBytes := h.MaxBodyBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		status := http.StatusBadRequest
+		if len(payload) >= int(maxBytes) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.error(w, r, status, fmt.Errorf("reading webhook body: %w", err))
+		return
+	}
+
+	tolerance := h.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+
+	if err := validatePayloadWithSecrets(payload, r.Header.Get(SignatureHeader), h.secrets, tolerance, true); err != nil {
+		h.error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	e, err := ParseEvent(payload)
+	if err != nil {
+		h.error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if h.Store != nil {
+		claimed, err := h.Store.Claim(r.Context(), e.ID)
+		if err != nil {
+			h.error(w, r, http.StatusInternalServerError, fmt.Errorf("claiming webhook event %s: %w", e.ID, err))
+			return
+		}
+
+		// The event has already been handled, acknowledge the duplicate.
+		if !claimed {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	if err := h.dispatch(r.Context(), e); err != nil {
+		status := statusCode(err)
+
+		// The callback acknowledged the event despite the error.
+		if status >= 200 && status <= 299 {
+			w.WriteHeader(status)
+			return
+		}
+
+		if h.Store != nil {
+			if rerr := h.Store.Release(r.Context(), e.ID); rerr != nil && h.ErrorLog != nil {
+				h.ErrorLog.Printf("webhooks: releasing event %s: %v", e.ID, rerr)
+			}
+		}
+
+		h.error(w, r, status, fmt.Errorf("handling webhook event %s (%s): %w", e.ID, e.Type, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatch calls the callbacks registered for the type of e.
+func (h *Handler) dispatch(ctx context.Context, e *Event) error {
+	for _, fn := range h.handlers[e.Type] {
+		if err := fn(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf("webhooks: %s %s: %v", r.Method, r.URL.Path, err)
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+// statusError is an error carrying the HTTP status code to respond with.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string   { return e.err.Error() }
+func (e *statusError) Unwrap() error   { return e.err }
+func (e *statusError) StatusCode() int { return e.status }
+
+// WithStatus annotates err with the HTTP status code a Handler responds with
+// when a callback returns it. For example, a 2xx status code acknowledges the
+// event even though handling it failed, so it will not be delivered again.
+func WithStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+
+	return &statusError{status: status, err: err}
+}
+
+// statusCode returns the HTTP status code a Handler responds with for a
+// callback error.
+func statusCode(err error) int {
+	var se interface{ StatusCode() int }
+	if errors.As(err, &se) {
+		return se.StatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
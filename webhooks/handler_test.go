@@ -0,0 +1,250 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+func newWebhookRequest(p *signedPayload) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(p.payload))
+	r.Header.Set(SignatureHeader, p.header)
+	return r
+}
+
+func newEventPayload(options ...func(*signedPayload)) *signedPayload {
+	return newSignedPayload(append([]func(*signedPayload){func(p *signedPayload) {
+		p.payload = testEventPayload
+	}}, options...)...)
+}
+
+func TestHandler_dispatch(t *testing.T) {
+	h := NewHandler(testSecret)
+
+	var got *gocancel.Letter
+	h.OnLetterSent(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+		if e.Type != EventLetterSent {
+			t.Errorf("Callback received event of type %q, want %q", e.Type, EventLetterSent)
+		}
+		got = letter
+		return nil
+	})
+	h.OnLetterFailed(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+		t.Errorf("letter.failed callback called for a letter.sent event")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(newEventPayload()))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", w.Code, http.StatusOK)
+	}
+	if got == nil || *got.ID != "26468553-08bb-47c4-a28c-d80dec6ef3b2" {
+		t.Errorf("Callback received letter %v", got)
+	}
+}
+
+func TestHandler_unhandledEvent(t *testing.T) {
+	h := NewHandler(testSecret)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(newEventPayload()))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_multipleSecrets(t *testing.T) {
+	h := NewHandler("wh_sig_old_secret", testSecret)
+
+	var calls int
+	h.On(EventLetterSent, func(ctx context.Context, e *Event) error {
+		calls++
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(newEventPayload()))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", w.Code, http.StatusOK)
+	}
+	if calls != 1 {
+		t.Errorf("Callback called %d times, want 1", calls)
+	}
+}
+
+func TestHandler_errors(t *testing.T) {
+	testcases := map[string]struct {
+		handler func() *Handler
+		request func() *http.Request
+		want    int
+	}{
+		"method not allowed": {
+			handler: func() *Handler { return NewHandler(testSecret) },
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/webhooks", nil) },
+			want:    http.StatusMethodNotAllowed,
+		},
+		"missing signature": {
+			handler: func() *Handler { return NewHandler(testSecret) },
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(testEventPayload))
+			},
+			want: http.StatusBadRequest,
+		},
+		"wrong secret": {
+			handler: func() *Handler { return NewHandler("wh_sig_other_secret") },
+			request: func() *http.Request { return newWebhookRequest(newEventPayload()) },
+			want:    http.StatusBadRequest,
+		},
+		"signature too old": {
+			handler: func() *Handler { return NewHandler(testSecret) },
+			request: func() *http.Request {
+				return newWebhookRequest(newEventPayload(func(p *signedPayload) {
+					p.timestamp = time.Now().Add(-time.Hour)
+				}))
+			},
+			want: http.StatusBadRequest,
+		},
+		"malformed event": {
+			handler: func() *Handler { return NewHandler(testSecret) },
+			request: func() *http.Request {
+				return newWebhookRequest(newSignedPayload(func(p *signedPayload) {
+					p.payload = []byte(`[]`)
+				}))
+			},
+			want: http.StatusBadRequest,
+		},
+		"body too large": {
+			handler: func() *Handler {
+				h := NewHandler(testSecret)
+				h.MaxBodyBytes = 16
+				return h
+			},
+			request: func() *http.Request { return newWebhookRequest(newEventPayload()) },
+			want:    http.StatusRequestEntityTooLarge,
+		},
+		"callback error": {
+			handler: func() *Handler {
+				h := NewHandler(testSecret)
+				h.OnLetterSent(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+					return errors.New("database unavailable")
+				})
+				return h
+			},
+			request: func() *http.Request { return newWebhookRequest(newEventPayload()) },
+			want:    http.StatusInternalServerError,
+		},
+		"callback error with status": {
+			handler: func() *Handler {
+				h := NewHandler(testSecret)
+				h.On(EventLetterSent, func(ctx context.Context, e *Event) error {
+					return WithStatus(errors.New("unknown letter"), http.StatusUnprocessableEntity)
+				})
+				return h
+			},
+			request: func() *http.Request { return newWebhookRequest(newEventPayload()) },
+			want:    http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.handler().ServeHTTP(w, tc.request())
+
+			if w.Code != tc.want {
+				t.Errorf("Handler responded with %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandler_acknowledgedError(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(testSecret)
+	h.ErrorLog = log.New(&buf, "", 0)
+	h.OnLetterSent(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+		return WithStatus(errors.New("letter already archived"), http.StatusAccepted)
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newWebhookRequest(newEventPayload()))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Handler responded with %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if body := w.Body.String(); body != "" {
+		t.Errorf("Handler responded with body %q, want empty", body)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Handler logged %q, want nothing", buf.String())
+	}
+}
+
+func TestNewHandler_noSecrets(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewHandler did not panic without secrets")
+		}
+	}()
+
+	NewHandler()
+}
+
+func TestHandler_server(t *testing.T) {
+	h := NewHandler(testSecret)
+
+	received := make(chan string, 1)
+	h.OnLetterSent(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+		received <- e.ID
+		return nil
+	})
+
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	p := newEventPayload()
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader(p.payload))
+	req.Header.Set(SignatureHeader, p.header)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Sending webhook returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if id := <-received; id != "evt_123" {
+		t.Errorf("Callback received event %q, want %q", id, "evt_123")
+	}
+}
+
+func TestWithStatus(t *testing.T) {
+	if WithStatus(nil, http.StatusOK) != nil {
+		t.Errorf("WithStatus(nil) returned non-nil error")
+	}
+
+	base := errors.New("base")
+	err := WithStatus(base, http.StatusAccepted)
+	if !errors.Is(err, base) {
+		t.Errorf("WithStatus error does not unwrap to the original error")
+	}
+	if got := statusCode(err); got != http.StatusAccepted {
+		t.Errorf("statusCode = %d, want %d", got, http.StatusAccepted)
+	}
+	if got := statusCode(base); got != http.StatusInternalServerError {
+		t.Errorf("statusCode = %d, want %d", got, http.StatusInternalServerError)
+	}
+}
@@ -109,21 +109,30 @@ func parseSignatureHeader(header string) (*signedHeader, error) {
 }
 
 func validatePayload(payload []byte, sigHeader string, secret string, tolerance time.Duration, enforceTolerance bool) error {
+	return validatePayloadWithSecrets(payload, sigHeader, []string{secret}, tolerance, enforceTolerance)
+}
+
+// validatePayloadWithSecrets validates the payload against the signature
+// header, accepting a signature made with any of the given secrets.
+func validatePayloadWithSecrets(payload []byte, sigHeader string, secrets []string, tolerance time.Duration, enforceTolerance bool) error {
 	header, err := parseSignatureHeader(sigHeader)
 	if err != nil {
 		return err
 	}
 
-	expectedSignature := ComputeSignature(header.timestamp, payload, secret)
 	expiredTimestamp := time.Since(header.timestamp) > tolerance
 	if enforceTolerance && expiredTimestamp {
 		return ErrTooOld
 	}
 
-	// Check all given v1 signatures, multiple signatures will be sent temporarily in the case of a rolled signature secret
-	for _, sig := range header.signatures {
-		if hmac.Equal(expectedSignature, sig) {
-			return nil
+	for _, secret := range secrets {
+		expectedSignature := ComputeSignature(header.timestamp, payload, secret)
+
+		// Check all given v1 signatures, multiple signatures will be sent temporarily in the case of a rolled signature secret
+		for _, sig := range header.signatures {
+			if hmac.Equal(expectedSignature, sig) {
+				return nil
+			}
 		}
 	}
 
@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UnsignedPayload represents a webhook payload to be signed by
+// GenerateTestSignedPayload.
+type UnsignedPayload struct {
+	// Payload is the body of the webhook.
+	Payload []byte
+
+	// Secrets are the signing secrets. A signature is generated for each of
+	// them, mimicking deliveries while a secret is being rotated.
+	Secrets []string
+
+	// Timestamp is the time of signing. Defaults to the current time.
+	Timestamp time.Time
+
+	// Scheme is the signature scheme. Defaults to the current scheme, v1.
+	Scheme string
+}
+
+// SignedPayload represents a webhook payload signed by
+// GenerateTestSignedPayload.
+type SignedPayload struct {
+	UnsignedPayload
+
+	// Signature is the signature made with the first secret.
+	Signature []byte
+
+	// Header is the value of the Gocxl-Signature header.
+	Header string
+}
+
+// GenerateTestSignedPayload signs a webhook payload the way GoCancel does. It
+// is meant for testing code that receives webhooks.
+func GenerateTestSignedPayload(p *UnsignedPayload) *SignedPayload {
+	sp := &SignedPayload{UnsignedPayload: *p}
+
+	if sp.Timestamp.IsZero() {
+		sp.Timestamp = time.Now()
+	}
+	if sp.Scheme == "" {
+		sp.Scheme = signingVersion
+	}
+
+	parts := []string{fmt.Sprintf("t=%d", sp.Timestamp.Unix())}
+	for i, secret := range sp.Secrets {
+		sig := ComputeSignature(sp.Timestamp, sp.Payload, secret)
+		if i == 0 {
+			sp.Signature = sig
+		}
+
+		parts = append(parts, fmt.Sprintf("%s=%s", sp.Scheme, hex.EncodeToString(sig)))
+	}
+	sp.Header = strings.Join(parts, ",")
+
+	return sp
+}
+
+// SignHeader returns a Gocxl-Signature header for payload, signed at t with
+// each of the given secrets.
+func SignHeader(payload []byte, secrets []string, t time.Time) string {
+	return GenerateTestSignedPayload(&UnsignedPayload{
+		Payload:   payload,
+		Secrets:   secrets,
+		Timestamp: t,
+	}).Header
+}
+
+// NewSignedRequest returns a webhook request delivering e to url, signed
+// with each of the given secrets. The request can be passed to an
+// http.Handler directly or sent using an http.Client.
+func NewSignedRequest(url string, e *Event, secrets ...string) (*http.Request, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, SignHeader(payload, secrets, time.Now()))
+
+	return req, nil
+}
@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocancel/gocancel-go"
+)
+
+func TestGenerateTestSignedPayload(t *testing.T) {
+	ts := time.Unix(1622116145, 0)
+	p := GenerateTestSignedPayload(&UnsignedPayload{
+		Payload:   testPayload,
+		Secrets:   []string{testSecret},
+		Timestamp: ts,
+	})
+
+	want := newSignedPayload(func(p *signedPayload) {
+		p.timestamp = ts
+	})
+	if p.Header != want.header {
+		t.Errorf("Header = %q, want %q", p.Header, want.header)
+	}
+	if p.Scheme != "v1" {
+		t.Errorf("Scheme = %q, want %q", p.Scheme, "v1")
+	}
+
+	if err := ValidatePayloadIgnoringTolerance(p.Payload, p.Header, testSecret); err != nil {
+		t.Errorf("Generated payload does not validate: %v", err)
+	}
+}
+
+func TestSignHeader_multipleSecrets(t *testing.T) {
+	secrets := []string{testSecret, testSecret + "_rolled_key"}
+	header := SignHeader(testPayload, secrets, time.Now())
+
+	if got := strings.Count(header, "v1="); got != 2 {
+		t.Errorf("Header %q contains %d signatures, want 2", header, got)
+	}
+
+	for _, secret := range secrets {
+		if err := ValidatePayload(testPayload, header, secret); err != nil {
+			t.Errorf("Header does not validate with secret %q: %v", secret, err)
+		}
+	}
+}
+
+func TestNewSignedRequest(t *testing.T) {
+	e := &Event{
+		ID:        "evt_123",
+		Type:      EventLetterSent,
+		CreatedAt: gocancel.Timestamp{Time: time.Date(2021, time.May, 27, 11, 49, 05, 0, time.UTC)},
+		Data:      json.RawMessage(`{"id":"b"}`),
+	}
+
+	req, err := NewSignedRequest("https://example.com/webhooks", e, testSecret)
+	if err != nil {
+		t.Fatalf("NewSignedRequest returned error: %v", err)
+	}
+
+	h := NewHandler(testSecret)
+
+	var got string
+	h.OnLetterSent(func(ctx context.Context, e *Event, letter *gocancel.Letter) error {
+		got = *letter.ID
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", w.Code, http.StatusOK)
+	}
+	if got != "b" {
+		t.Errorf("Callback received letter %q, want %q", got, "b")
+	}
+}
@@ -0,0 +1,228 @@
+package webhooks
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultStoreTTL is how long event IDs are remembered by a Store, unless
+// configured otherwise. It should exceed the period during which GoCancel
+// retries failed deliveries.
+const DefaultStoreTTL = 24 * time.Hour
+
+// Store records the IDs of processed webhook events, so that events that are
+// delivered more than once, e.g. on retries or replays within the signature
+// tolerance, are only processed once. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Claim records id as processed. It reports false if id has already
+	// been recorded and has not expired yet.
+	Claim(ctx context.Context, id string) (bool, error)
+
+	// Release forgets id, so that the next delivery of the event is
+	// processed again. It is called when processing the event failed.
+	Release(ctx context.Context, id string) error
+}
+
+// MemoryStore is a Store keeping event IDs in memory. Expired IDs are evicted
+// when new IDs are claimed. It optionally remembers at most a fixed number of
+// IDs, evicting the least recently claimed ID when full.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List // front is the most recently claimed entry
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	id      string
+	expires time.Time
+}
+
+// NewMemoryStore returns a MemoryStore remembering up to capacity event IDs
+// for ttl. A capacity of zero or less means no limit, a ttl of zero or less
+// means DefaultStoreTTL.
+func NewMemoryStore(capacity int, ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultStoreTTL
+	}
+
+	return &MemoryStore{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Claim implements the Store interface.
+func (s *MemoryStore) Claim(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	// All entries have the same ttl, so the least recently claimed entries
+	// expire first.
+	for el := s.order.Back(); el != nil && !now.Before(el.Value.(*memoryEntry).expires); el = s.order.Back() {
+		s.remove(el)
+	}
+
+	if el, ok := s.entries[id]; ok {
+		if now.Before(el.Value.(*memoryEntry).expires) {
+			return false, nil
+		}
+		s.remove(el)
+	}
+
+	s.entries[id] = s.order.PushFront(&memoryEntry{id: id, expires: now.Add(s.ttl)})
+
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+
+	return true, nil
+}
+
+// Release implements the Store interface.
+func (s *MemoryStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[id]; ok {
+		s.remove(el)
+	}
+
+	return nil
+}
+
+// Len returns the number of event IDs currently remembered, including IDs
+// that have expired since the last call to Claim.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*memoryEntry).id)
+}
+
+// FileStore is a Store persisting event IDs in a JSON file, so they survive
+// restarts. The file is rewritten on every change, which makes FileStore
+// suitable for low volumes of webhooks received by a single process.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]time.Time
+
+	now func() time.Time
+}
+
+// NewFileStore returns a FileStore persisting event IDs for ttl in the file at
+// path, loading the IDs already stored in it. A ttl of zero or less means
+// DefaultStoreTTL.
+func NewFileStore(path string, ttl time.Duration) (*FileStore, error) {
+	if ttl <= 0 {
+		ttl = DefaultStoreTTL
+	}
+
+	s := &FileStore{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Claim implements the Store interface.
+func (s *FileStore) Claim(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expires, ok := s.entries[id]; ok && now.Before(expires) {
+		return false, nil
+	}
+
+	s.entries[id] = now.Add(s.ttl)
+	if err := s.save(now); err != nil {
+		delete(s.entries, id)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Release implements the Store interface.
+func (s *FileStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[id]; !ok {
+		return nil
+	}
+
+	delete(s.entries, id)
+	return s.save(s.now())
+}
+
+// save prunes the expired entries and atomically replaces the file with the
+// remaining ones.
+func (s *FileStore) save(now time.Time) error {
+	for id, expires := range s.entries {
+		if !now.Before(expires) {
+			delete(s.entries, id)
+		}
+	}
+
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
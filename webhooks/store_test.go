@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+// testStore exercises the Store contract using a clock controlled by the test.
+func testStore(t *testing.T, s Store, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	claim := func(id string, want bool) {
+		t.Helper()
+		got, err := s.Claim(ctx, id)
+		if err != nil {
+			t.Fatalf("Claim(%q) returned error: %v", id, err)
+		}
+		if got != want {
+			t.Errorf("Claim(%q) = %t, want %t", id, got, want)
+		}
+	}
+
+	claim("evt_1", true)
+	claim("evt_1", false)
+	claim("evt_2", true)
+
+	if err := s.Release(ctx, "evt_1"); err != nil {
+		t.Fatalf("Release returned error: %v", err)
+	}
+	claim("evt_1", true)
+
+	if err := s.Release(ctx, "evt_unknown"); err != nil {
+		t.Errorf("Release of unknown ID returned error: %v", err)
+	}
+
+	advance(2 * time.Hour)
+	claim("evt_2", true)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(0, time.Hour)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	testStore(t, s, func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestMemoryStore_capacity(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2, time.Hour)
+
+	for _, id := range []string{"evt_1", "evt_2", "evt_3"} {
+		if ok, _ := s.Claim(ctx, id); !ok {
+			t.Fatalf("Claim(%q) = false, want true", id)
+		}
+	}
+
+	if got := s.Len(); got != 2 {
+		t.Errorf("MemoryStore.Len = %d, want 2", got)
+	}
+	if ok, _ := s.Claim(ctx, "evt_1"); !ok {
+		t.Errorf("Claim of evicted ID = false, want true")
+	}
+	if ok, _ := s.Claim(ctx, "evt_3"); ok {
+		t.Errorf("Claim of remembered ID = true, want false")
+	}
+}
+
+func TestMemoryStore_evictsExpired(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(0, time.Hour)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 1000; i++ {
+		if _, err := s.Claim(ctx, fmt.Sprintf("evt_%d", i)); err != nil {
+			t.Fatalf("Claim returned error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	if n := s.Len(); n > 60 {
+		t.Errorf("Len = %d, want at most 60 unexpired IDs", n)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+
+	s, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	testStore(t, s, func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestFileStore_persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.json")
+
+	s, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	if ok, err := s.Claim(ctx, "evt_1"); !ok || err != nil {
+		t.Fatalf("Claim = (%t, %v), want (true, nil)", ok, err)
+	}
+
+	s, err = NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	if ok, _ := s.Claim(ctx, "evt_1"); ok {
+		t.Errorf("Claim after reopening the store = true, want false")
+	}
+}
+
+func TestHandler_store(t *testing.T) {
+	h := NewHandler(testSecret)
+	h.Store = NewMemoryStore(0, 0)
+
+	var calls int
+	fail := true
+	h.On(EventLetterSent, func(ctx context.Context, e *Event) error {
+		calls++
+		if fail {
+			return WithStatus(errTest, http.StatusServiceUnavailable)
+		}
+		return nil
+	})
+
+	p := newEventPayload()
+	serve := func() int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newWebhookRequest(p))
+		return w.Code
+	}
+
+	// A failed delivery is released, so the retry is handled again.
+	if got := serve(); got != http.StatusServiceUnavailable {
+		t.Errorf("Handler responded with %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	fail = false
+	if got := serve(); got != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", got, http.StatusOK)
+	}
+
+	// A duplicate of a handled delivery is acknowledged but not handled.
+	if got := serve(); got != http.StatusOK {
+		t.Errorf("Handler responded with %d, want %d", got, http.StatusOK)
+	}
+
+	if calls != 2 {
+		t.Errorf("Callback called %d times, want 2", calls)
+	}
+}
@@ -2,6 +2,7 @@ package gocancel
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -21,6 +22,7 @@ func TestWebhook_marshal(t *testing.T) {
 		Events:    []*string{String("organization.created")},
 		Locales:   []*string{String("nl-NL")},
 		Active:    Bool(true),
+		Secret:    String("wh_sig_secret"),
 		Metadata:  &AccountMetadata{"foo": "bar"},
 		CreatedAt: &Timestamp{time.Date(2021, time.May, 27, 11, 49, 05, 0, time.UTC)},
 		UpdatedAt: &Timestamp{time.Date(2021, time.May, 27, 11, 49, 05, 0, time.UTC)},
@@ -33,6 +35,7 @@ func TestWebhook_marshal(t *testing.T) {
 			"events": ["organization.created"],
 			"locales": ["nl-NL"],
 			"active": true,
+			"secret": "wh_sig_secret",
 			"metadata": {
 				"foo": "bar"
 			},
@@ -80,6 +83,32 @@ func TestWebhooksService_List(t *testing.T) {
 	})
 }
 
+func TestWebhooksService_ListAll(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("cursor") == "" {
+			testFormValues(t, r, url.Values{"limit": {"1"}})
+			fmt.Fprint(w, `{"webhooks": [{"id":"a"}], "metadata": {"next_cursor": "def"}}`)
+			return
+		}
+		testFormValues(t, r, url.Values{"cursor": {"def"}, "limit": {"1"}})
+		fmt.Fprint(w, `{"webhooks": [{"id":"b"}], "metadata": {"previous_cursor": "abc"}}`)
+	})
+
+	ctx := context.Background()
+	got, err := client.Webhooks.ListAll(ctx, &WebhooksListOptions{Limit: 1}).Collect()
+	if err != nil {
+		t.Fatalf("Webhooks.ListAll returned error: %v", err)
+	}
+
+	if len(got) != 2 || *got[0].ID != "a" || *got[1].ID != "b" {
+		t.Errorf("Webhooks.ListAll returned %+v, want items a and b", got)
+	}
+}
+
 func TestWebhooksService_Get(t *testing.T) {
 	client, mux, _, teardown := setup()
 	defer teardown()
@@ -114,3 +143,220 @@ func TestWebhooksService_Get(t *testing.T) {
 		return resp, err
 	})
 }
+
+func TestWebhookRequest_marshal(t *testing.T) {
+	testJSONMarshal(t, &WebhookRequest{}, `{}`)
+	testJSONMarshal(t, &WebhookRequest{Events: Strings(), Locales: Strings()}, `{"events":[],"locales":[]}`)
+
+	o := &WebhookRequest{
+		URL:      "https://example.com",
+		Events:   Strings("letter.sent"),
+		Locales:  Strings("nl-NL"),
+		Metadata: AccountMetadata{"foo": "bar"},
+		Active:   Bool(false),
+	}
+	want := `
+		{
+			"url": "https://example.com",
+			"events": ["letter.sent"],
+			"locales": ["nl-NL"],
+			"metadata": {
+				"foo": "bar"
+			},
+			"active": false
+		}
+	`
+	testJSONMarshal(t, o, want)
+}
+
+func TestWebhooksService_Create(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	input := &WebhookRequest{URL: "https://example.com", Events: Strings("letter.sent")}
+
+	mux.HandleFunc("/api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		v := new(WebhookRequest)
+		_ = json.NewDecoder(r.Body).Decode(v)
+
+		if !cmp.Equal(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"webhook": {"id":"b","secret":"s"}}`)
+	})
+
+	ctx := context.Background()
+	webhook, _, err := client.Webhooks.Create(ctx, input)
+	if err != nil {
+		t.Fatalf("Webhooks.Create returned error: %v", err)
+	}
+
+	want := &Webhook{ID: String("b"), Secret: String("s")}
+	if !cmp.Equal(webhook, want) {
+		t.Errorf("Webhooks.Create returned %+v, want %+v", webhook, want)
+	}
+
+	const methodName = "Create"
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		got, resp, err := client.Webhooks.Create(ctx, input)
+		if got != nil {
+			t.Errorf("testNewRequestAndDoFailure %v = %#v, want nil", methodName, got)
+		}
+		return resp, err
+	})
+}
+
+func TestWebhooksService_Update(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	input := &WebhookRequest{Active: Bool(false)}
+
+	mux.HandleFunc("/api/v1/webhooks/b", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+
+		v := new(WebhookRequest)
+		_ = json.NewDecoder(r.Body).Decode(v)
+
+		if !cmp.Equal(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"webhook": {"id":"b","active":false}}`)
+	})
+
+	ctx := context.Background()
+	webhook, _, err := client.Webhooks.Update(ctx, "b", input)
+	if err != nil {
+		t.Fatalf("Webhooks.Update returned error: %v", err)
+	}
+
+	want := &Webhook{ID: String("b"), Active: Bool(false)}
+	if !cmp.Equal(webhook, want) {
+		t.Errorf("Webhooks.Update returned %+v, want %+v", webhook, want)
+	}
+
+	const methodName = "Update"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Webhooks.Update(ctx, "\n", input)
+		return err
+	})
+
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		got, resp, err := client.Webhooks.Update(ctx, "b", input)
+		if got != nil {
+			t.Errorf("testNewRequestAndDoFailure %v = %#v, want nil", methodName, got)
+		}
+		return resp, err
+	})
+}
+
+func TestWebhooksService_Delete(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/webhooks/b", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	_, err := client.Webhooks.Delete(ctx, "b")
+	if err != nil {
+		t.Fatalf("Webhooks.Delete returned error: %v", err)
+	}
+
+	const methodName = "Delete"
+	testBadOptions(t, methodName, func() (err error) {
+		_, err = client.Webhooks.Delete(ctx, "\n")
+		return err
+	})
+
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		return client.Webhooks.Delete(ctx, "b")
+	})
+}
+
+func TestWebhooksService_RollSecret(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	input := &RollWebhookSecretRequest{ExpiresIn: 3600}
+
+	mux.HandleFunc("/api/v1/webhooks/b/roll_secret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		v := new(RollWebhookSecretRequest)
+		_ = json.NewDecoder(r.Body).Decode(v)
+
+		if !cmp.Equal(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"webhook": {"id":"b","secret":"new"}}`)
+	})
+
+	ctx := context.Background()
+	webhook, _, err := client.Webhooks.RollSecret(ctx, "b", input)
+	if err != nil {
+		t.Fatalf("Webhooks.RollSecret returned error: %v", err)
+	}
+
+	want := &Webhook{ID: String("b"), Secret: String("new")}
+	if !cmp.Equal(webhook, want) {
+		t.Errorf("Webhooks.RollSecret returned %+v, want %+v", webhook, want)
+	}
+
+	const methodName = "RollSecret"
+	testBadOptions(t, methodName, func() (err error) {
+		_, _, err = client.Webhooks.RollSecret(ctx, "\n", input)
+		return err
+	})
+
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		got, resp, err := client.Webhooks.RollSecret(ctx, "b", input)
+		if got != nil {
+			t.Errorf("testNewRequestAndDoFailure %v = %#v, want nil", methodName, got)
+		}
+		return resp, err
+	})
+}
+
+func TestWebhooksService_SendTestEvent(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	input := &SendWebhookTestEventRequest{Event: "letter.sent"}
+
+	mux.HandleFunc("/api/v1/webhooks/b/test", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+
+		v := new(SendWebhookTestEventRequest)
+		_ = json.NewDecoder(r.Body).Decode(v)
+
+		if !cmp.Equal(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	ctx := context.Background()
+	_, err := client.Webhooks.SendTestEvent(ctx, "b", input)
+	if err != nil {
+		t.Fatalf("Webhooks.SendTestEvent returned error: %v", err)
+	}
+
+	const methodName = "SendTestEvent"
+	testBadOptions(t, methodName, func() (err error) {
+		_, err = client.Webhooks.SendTestEvent(ctx, "\n", input)
+		return err
+	})
+
+	testNewRequestAndDoFailure(t, methodName, client, func() (*Response, error) {
+		return client.Webhooks.SendTestEvent(ctx, "b", input)
+	})
+}